@@ -0,0 +1,35 @@
+package routines
+
+import "time"
+
+func Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+func SleepUntil(condition func() bool) {
+	for !condition() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func SleepUntilOrTimeout(condition func() bool, duration time.Duration) {
+	timer := time.After(duration)
+	for !condition() {
+		select {
+		case <-timer:
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func SleepForDone(done <-chan struct{}) {
+	<-done
+}
+
+func SleepForDoneOrTimeout(done <-chan struct{}, duration time.Duration) {
+	select {
+	case <-done:
+	case <-time.After(duration):
+	}
+}
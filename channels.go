@@ -0,0 +1,106 @@
+package routines
+
+import "time"
+
+// WaitForAll waits for values to be received from all channels, closed channels count as received
+func (r *Routine) WaitForAll(chans ...<-chan struct{}) {
+	r.waitForChannels(r.caller(), StepWaitForAll, len(chans), 0, chans)
+}
+
+func (r *Routine) WaitForAllOrTimeout(duration time.Duration, chans ...<-chan struct{}) {
+	r.waitForChannels(r.caller(), StepWaitForAllOrTimeout, len(chans), duration, chans)
+}
+
+// WaitForAny waits for value to be received from any of channels, it returns index of channel that fired first or -1
+// if none fired yet, index stays available after wait is done
+func (r *Routine) WaitForAny(chans ...<-chan struct{}) int {
+	return r.waitForChannels(r.caller(), StepWaitForAny, 1, 0, chans).index()
+}
+
+// WaitForAnyOrTimeout returns -1 if time passed before any of channels fired
+func (r *Routine) WaitForAnyOrTimeout(duration time.Duration, chans ...<-chan struct{}) int {
+	return r.waitForChannels(r.caller(), StepWaitForAnyOrTimeout, 1, duration, chans).index()
+}
+
+// WaitForQuorum waits for values to be received from at least n of channels
+func (r *Routine) WaitForQuorum(n int, chans ...<-chan struct{}) {
+	r.waitForChannels(r.caller(), StepWaitForQuorum, n, 0, chans)
+}
+
+func (r *Routine) WaitForQuorumOrTimeout(n int, duration time.Duration, chans ...<-chan struct{}) {
+	r.waitForChannels(r.caller(), StepWaitForQuorumOrTimeout, n, duration, chans)
+}
+
+type channelsState struct {
+	fired []bool
+	count int
+	first int
+}
+
+func (c *channelsState) index() int {
+	if c == nil {
+		return -1
+	}
+	return c.first
+}
+
+// State of channels is returned even if wait is not running or already done, timeout is used only if it's positive
+func (r *Routine) waitForChannels(
+	pc uintptr, kind StepKind, need int, timeout time.Duration, chans []<-chan struct{},
+) *channelsState {
+	caller, pop := r.pushToStack(pc)
+	defer pop()
+
+	state, _ := r.values[caller].(*channelsState)
+	if !r.running() || r.isExecuted(caller) {
+		return state
+	}
+	s := r.step(caller, kind)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return state
+	}
+	r.reach(s)
+
+	state = executionValues(r, caller, func() *channelsState {
+		return &channelsState{
+			fired: make([]bool, len(chans)),
+			first: -1,
+		}
+	})
+
+	r.addExecution(caller)
+	r.run(caller, s, func() {
+		for i, ch := range chans {
+			if state.fired[i] {
+				continue
+			}
+
+			select {
+			case <-ch:
+				state.fired[i] = true
+				state.count++
+				if state.first == -1 {
+					state.first = i
+				}
+			default:
+			}
+		}
+
+		if state.count >= need || r.consumeSkipCondition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+			return
+		}
+
+		if timeout <= 0 {
+			return
+		}
+		if deadline := r.executionTimer(caller, timeout); r.isExpired(deadline) {
+			r.markAsExecuted(caller)
+			r.expire(s, deadline)
+		}
+	})
+
+	return state
+}
@@ -0,0 +1,88 @@
+package routines_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRoutine_WaitForAll(t *testing.T) {
+	r := routines.StartRoutine()
+
+	chans := []chan struct{}{make(chan struct{}, 1), make(chan struct{}), make(chan struct{}, 1)}
+	e1 := 0
+	for i := 0; i < 6; i++ {
+		r.WaitForAll(chans[0], chans[1], chans[2])
+		r.Do(func() {
+			e1++
+		})
+		r.End()
+
+		switch i {
+		case 0:
+			chans[2] <- struct{}{}
+		case 2:
+			chans[0] <- struct{}{}
+		case 3:
+			test.Equal(t, e1, 0)
+			close(chans[1])
+		}
+	}
+
+	test.Equal(t, e1, 1)
+	test.True(t, r.Completed())
+}
+
+func TestRoutine_WaitForAny(t *testing.T) {
+	r := routines.StartRoutine()
+
+	a, b := make(chan struct{}), make(chan struct{}, 1)
+	var indexes []int
+	fired := -1
+	for i := 0; i < 4; i++ {
+		index := r.WaitForAny(a, b)
+		indexes = append(indexes, index)
+		r.Do(func() {
+			fired = index
+		})
+		r.End()
+
+		if i == 1 {
+			b <- struct{}{}
+		}
+	}
+
+	test.EqualEl(t, indexes, []int{-1, -1, 1, 1})
+	test.Equal(t, fired, 1)
+	test.True(t, r.Completed())
+}
+
+func TestRoutine_WaitForQuorumOrTimeout(t *testing.T) {
+	for _, quorum := range []bool{true, false} {
+		r := routines.StartRoutine()
+
+		chans := []chan struct{}{make(chan struct{}), make(chan struct{}), make(chan struct{})}
+		close(chans[0])
+		if quorum {
+			close(chans[2])
+		}
+
+		index := 0
+		start := time.Now()
+		for !r.Completed() && time.Since(start) < maxDuration {
+			r.WaitForQuorumOrTimeout(2, waitTime*10, chans[0], chans[1], chans[2])
+			index = r.WaitForAnyOrTimeout(waitTime*10, chans[1])
+			r.End()
+		}
+
+		test.True(t, r.Completed())
+		test.Equal(t, index, -1)
+		if quorum {
+			test.True(t, time.Since(start) < waitTime*20)
+		} else {
+			test.True(t, time.Since(start) >= waitTime*20)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package routines
+
+import "time"
+
+// Clock provides current wall time, routine time and absolute waits are based on it
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
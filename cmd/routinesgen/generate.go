@@ -0,0 +1,658 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"maps"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	routinesPath = "github.com/mymmrac/routines"
+	directive    = "//routines:generate"
+)
+
+var waiters = map[string]string{
+	"Sleep":                 "WaitFor",
+	"SleepUntil":            "WaitUntil",
+	"SleepUntilOrTimeout":   "WaitUntilOrTimeout",
+	"SleepForDone":          "WaitForDone",
+	"SleepForDoneOrTimeout": "WaitForDoneOrTimeout",
+}
+
+type generator struct {
+	fset     *token.FileSet
+	routines string
+	routine  string
+
+	hoisted      []string
+	hoistedNames map[string]bool
+	segments     []*segment
+	kinds        map[string]rangeKind
+}
+
+// Kind of value that range loop with waits iterates over, only kinds that can be converted to Loop or Repeat are known
+type rangeKind int
+
+const (
+	rangeUnknown rangeKind = iota
+	rangeInt
+	rangeSlice
+	rangeMap
+)
+
+// Segment is a part of generated function that is executed as a separate step
+type segment struct {
+	pos     token.Pos
+	defined map[string]bool
+	used    map[string]bool
+}
+
+func generate(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	routinesName := ""
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if importPath != routinesPath {
+			continue
+		}
+
+		routinesName = path.Base(routinesPath)
+		if spec.Name != nil {
+			routinesName = spec.Name.Name
+		}
+	}
+
+	kinds := make(map[string]rangeKind)
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && (decl.Tok == token.VAR || decl.Tok == token.CONST) {
+			for _, spec := range decl.Specs {
+				declareKinds(kinds, spec.(*ast.ValueSpec))
+			}
+		}
+	}
+
+	var funcs []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !hasDirective(fn.Doc) {
+			continue
+		}
+		if routinesName == "" {
+			return nil, fmt.Errorf("%s: %s is not imported", filename, routinesPath)
+		}
+
+		g := &generator{
+			fset:         fset,
+			routines:     routinesName,
+			hoistedNames: make(map[string]bool),
+			kinds:        maps.Clone(kinds),
+		}
+		code, err := g.function(fn)
+		if err != nil {
+			return nil, err
+		}
+		funcs = append(funcs, code)
+	}
+	if len(funcs) == 0 {
+		return nil, nil
+	}
+
+	body := strings.Join(funcs, "\n")
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by routinesgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", file.Name.Name)
+	var std, other []string
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if importPath != routinesPath && !strings.Contains(body, name+".") {
+			continue
+		}
+
+		line := spec.Path.Value
+		if spec.Name != nil {
+			line = spec.Name.Name + " " + line
+		}
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			other = append(other, line)
+		} else {
+			std = append(std, line)
+		}
+	}
+
+	out.WriteString("import (\n")
+	for _, line := range std {
+		out.WriteString(line + "\n")
+	}
+	if len(std) > 0 && len(other) > 0 {
+		out.WriteString("\n")
+	}
+	for _, line := range other {
+		out.WriteString(line + "\n")
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(body)
+
+	return format.Source(out.Bytes())
+}
+
+func hasDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		if strings.TrimSpace(comment.Text) == directive {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) function(fn *ast.FuncDecl) (string, error) {
+	if fn.Type.Results != nil && len(fn.Type.Results.List) > 0 {
+		return "", g.errorf(fn.Pos(), "%s: functions with results are not supported", fn.Name.Name)
+	}
+
+	g.routine = g.routineName(fn)
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			g.kinds[name.Name] = typeKind(field.Type)
+		}
+	}
+
+	body, err := g.statements(fn.Body.List, 0)
+	if err != nil {
+		return "", err
+	}
+	if err = g.checkSegments(); err != nil {
+		return "", err
+	}
+
+	var params []string
+	params = append(params, fmt.Sprintf("%s *%s.Routine", g.routine, g.routines))
+	for _, field := range fn.Type.Params.List {
+		params = append(params, g.field(field))
+	}
+
+	var b strings.Builder
+	b.WriteString("func ")
+	if fn.Recv != nil {
+		fmt.Fprintf(&b, "(%s) ", g.field(fn.Recv.List[0]))
+	}
+	b.WriteString(fn.Name.Name + "Routine")
+	if fn.Type.TypeParams != nil {
+		var typeParams []string
+		for _, field := range fn.Type.TypeParams.List {
+			typeParams = append(typeParams, g.field(field))
+		}
+		fmt.Fprintf(&b, "[%s]", strings.Join(typeParams, ", "))
+	}
+	fmt.Fprintf(&b, "(%s) func() {\n", strings.Join(params, ", "))
+	for _, hoisted := range g.hoisted {
+		b.WriteString(hoisted + "\n")
+	}
+	b.WriteString("return func() {\n")
+	fmt.Fprintf(&b, "%s.Start()\n", g.routine)
+	b.WriteString(body)
+	fmt.Fprintf(&b, "%s.End()\n", g.routine)
+	b.WriteString("}\n}\n")
+	return b.String(), nil
+}
+
+func (g *generator) routineName(fn *ast.FuncDecl) string {
+	used := make(map[string]bool)
+	ast.Inspect(fn, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			used[ident.Name] = true
+		}
+		return true
+	})
+
+	name := "r"
+	for i := 1; used[name]; i++ {
+		name = fmt.Sprintf("r%d", i)
+	}
+	return name
+}
+
+func (g *generator) statements(stmts []ast.Stmt, depth int) (string, error) {
+	var b strings.Builder
+
+	var group []ast.Stmt
+	flush := func() {
+		if len(group) == 0 {
+			return
+		}
+
+		seg := g.segment(group[0].Pos())
+		fmt.Fprintf(&b, "%s.Do(func() {\n", g.routine)
+		for _, stmt := range group {
+			g.collect(seg, stmt)
+			b.WriteString(g.node(stmt) + "\n")
+		}
+		b.WriteString("})\n")
+		group = nil
+	}
+
+	for _, stmt := range stmts {
+		if !g.hasWaits(stmt) {
+			if err := g.checkControlFlow(stmt); err != nil {
+				return "", err
+			}
+
+			hoisted, err := g.hoist(stmt)
+			if err != nil {
+				return "", err
+			}
+			group = append(group, hoisted...)
+			continue
+		}
+		flush()
+
+		code, err := g.step(stmt, depth)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(code)
+	}
+	flush()
+
+	return b.String(), nil
+}
+
+func (g *generator) step(stmt ast.Stmt, depth int) (string, error) {
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			break
+		}
+		waiter, ok := g.waiter(call)
+		if !ok {
+			break
+		}
+
+		seg := g.segment(call.Pos())
+		var args []string
+		for _, arg := range call.Args {
+			g.collect(seg, arg)
+			args = append(args, g.node(arg))
+		}
+		return fmt.Sprintf("%s.%s(%s)\n", g.routine, waiter, strings.Join(args, ", ")), nil
+	case *ast.BlockStmt:
+		return g.statements(s.List, depth)
+	case *ast.ForStmt:
+		return g.forLoop(s, depth)
+	case *ast.RangeStmt:
+		return g.rangeLoop(s, depth)
+	}
+
+	return "", g.errorf(stmt.Pos(), "waits inside %s are not supported", describe(stmt))
+}
+
+func (g *generator) forLoop(s *ast.ForStmt, depth int) (string, error) {
+	init, ok := s.Init.(*ast.AssignStmt)
+	if !ok || init.Tok != token.DEFINE || len(init.Lhs) != 1 || len(init.Rhs) != 1 {
+		return "", g.errorf(s.Pos(), "loops with waits must have form: for i := start; i < end; i++")
+	}
+	index, ok := init.Lhs[0].(*ast.Ident)
+	if !ok {
+		return "", g.errorf(s.Pos(), "loops with waits must have form: for i := start; i < end; i++")
+	}
+
+	cond, ok := s.Cond.(*ast.BinaryExpr)
+	if !ok || cond.Op != token.LSS || !isIdent(cond.X, index.Name) {
+		return "", g.errorf(s.Pos(), "loops with waits must have form: for i := start; i < end; i++")
+	}
+
+	post, ok := s.Post.(*ast.IncDecStmt)
+	if !ok || post.Tok != token.INC || !isIdent(post.X, index.Name) {
+		return "", g.errorf(s.Pos(), "loops with waits must have form: for i := start; i < end; i++")
+	}
+
+	seg := g.segment(s.Pos())
+	g.collect(seg, init.Rhs[0])
+	g.collect(seg, cond.Y)
+
+	body, err := g.statements(s.Body.List, depth+1)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s.Loop(%s, %s, func(%s int) {\n%s})\n",
+		g.routine, g.node(init.Rhs[0]), g.node(cond.Y), index.Name, body), nil
+}
+
+func (g *generator) rangeLoop(s *ast.RangeStmt, depth int) (string, error) {
+	if s.Value != nil || (s.Key != nil && s.Tok != token.DEFINE) {
+		return "", g.errorf(s.Pos(), "range loops with waits must have form: for range n or for i := range n")
+	}
+
+	keyed := s.Key != nil && !isIdent(s.Key, "_")
+
+	var count string
+	switch exprKind(g.kinds, s.X) {
+	case rangeInt:
+		count = g.node(s.X)
+	case rangeSlice:
+		count = "len(" + g.node(s.X) + ")"
+	case rangeMap:
+		if keyed {
+			return "", g.errorf(s.Pos(), "range loops with waits over map keys are not supported, range over sorted keys instead")
+		}
+		count = "len(" + g.node(s.X) + ")"
+	default:
+		return "", g.errorf(s.X.Pos(),
+			"range loops with waits must be over int, slice or map, type of %s is unknown", g.node(s.X))
+	}
+
+	seg := g.segment(s.Pos())
+	g.collect(seg, s.X)
+
+	body, err := g.statements(s.Body.List, depth+1)
+	if err != nil {
+		return "", err
+	}
+
+	if !keyed {
+		return fmt.Sprintf("%s.Repeat(%s, func() {\n%s})\n", g.routine, count, body), nil
+	}
+	return fmt.Sprintf("%s.Loop(0, %s, func(%s int) {\n%s})\n", g.routine, count, g.node(s.Key), body), nil
+}
+
+// Kind is known for literals, builtin len and cap, parameters and variables declared with explicit type
+func exprKind(kinds map[string]rangeKind, expr ast.Expr) rangeKind {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return exprKind(kinds, x.X)
+	case *ast.BasicLit:
+		if x.Kind == token.INT {
+			return rangeInt
+		}
+	case *ast.Ident:
+		return kinds[x.Name]
+	case *ast.CallExpr:
+		if isIdent(x.Fun, "len") || isIdent(x.Fun, "cap") || isIdent(x.Fun, "int") {
+			return rangeInt
+		}
+	case *ast.BinaryExpr:
+		switch x.Op {
+		case token.ADD, token.SUB, token.MUL, token.QUO, token.REM:
+			if exprKind(kinds, x.X) == rangeInt && exprKind(kinds, x.Y) == rangeInt {
+				return rangeInt
+			}
+		}
+	}
+	return rangeUnknown
+}
+
+func typeKind(typ ast.Expr) rangeKind {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if t.Name == "int" {
+			return rangeInt
+		}
+	case *ast.ArrayType, *ast.Ellipsis:
+		return rangeSlice
+	case *ast.MapType:
+		return rangeMap
+	}
+	return rangeUnknown
+}
+
+// Untyped constants and variables take kind of their values
+func declareKinds(kinds map[string]rangeKind, spec *ast.ValueSpec) {
+	for i, name := range spec.Names {
+		switch {
+		case spec.Type != nil:
+			kinds[name.Name] = typeKind(spec.Type)
+		case i < len(spec.Values):
+			kinds[name.Name] = exprKind(kinds, spec.Values[i])
+		}
+	}
+}
+
+// Hoists variable declarations to the generated function, so they can be shared between steps and ticks
+func (g *generator) hoist(stmt ast.Stmt) ([]ast.Stmt, error) {
+	declStmt, ok := stmt.(*ast.DeclStmt)
+	if !ok {
+		return []ast.Stmt{stmt}, nil
+	}
+	decl, ok := declStmt.Decl.(*ast.GenDecl)
+	if !ok || decl.Tok != token.VAR {
+		return []ast.Stmt{stmt}, nil
+	}
+
+	var stmts []ast.Stmt
+	for _, spec := range decl.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+		if valueSpec.Type == nil {
+			stmts = append(stmts, &ast.DeclStmt{Decl: &ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{valueSpec}}})
+			continue
+		}
+
+		var lhs []ast.Expr
+		for _, name := range valueSpec.Names {
+			if g.hoistedNames[name.Name] {
+				return nil, g.errorf(name.Pos(), "variable %s is declared more than once", name.Name)
+			}
+			g.hoistedNames[name.Name] = true
+			lhs = append(lhs, name)
+		}
+		g.hoisted = append(g.hoisted, "var "+g.node(&ast.ValueSpec{Names: valueSpec.Names, Type: valueSpec.Type}))
+		declareKinds(g.kinds, valueSpec)
+
+		// Hoisted variables are assigned where they were declared, so they are reset on each pass and after restart
+		if len(valueSpec.Values) > 0 {
+			stmts = append(stmts, &ast.AssignStmt{Lhs: lhs, Tok: token.ASSIGN, Rhs: valueSpec.Values})
+		} else {
+			var rhs []ast.Expr
+			for range valueSpec.Names {
+				rhs = append(rhs, &ast.StarExpr{X: &ast.CallExpr{Fun: ast.NewIdent("new"), Args: []ast.Expr{valueSpec.Type}}})
+			}
+			stmts = append(stmts, &ast.AssignStmt{Lhs: lhs, Tok: token.ASSIGN, Rhs: rhs})
+		}
+	}
+	return stmts, nil
+}
+
+func (g *generator) segment(pos token.Pos) *segment {
+	seg := &segment{
+		pos:     pos,
+		defined: make(map[string]bool),
+		used:    make(map[string]bool),
+	}
+	g.segments = append(g.segments, seg)
+	return seg
+}
+
+func (g *generator) collect(seg *segment, node ast.Node) {
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		if n.Tok == token.DEFINE {
+			for _, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+					seg.defined[ident.Name] = true
+				}
+			}
+		}
+	case *ast.DeclStmt:
+		if decl, ok := n.Decl.(*ast.GenDecl); ok {
+			for _, spec := range decl.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					for _, name := range valueSpec.Names {
+						seg.defined[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	ast.Inspect(node, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			seg.used[ident.Name] = true
+		}
+		return true
+	})
+}
+
+// Variables defined inside one step are not visible to others, they must be declared with explicit type to be hoisted
+func (g *generator) checkSegments() error {
+	for i, seg := range g.segments {
+		for name := range seg.defined {
+			for _, next := range g.segments[i+1:] {
+				if next.defined[name] {
+					break
+				}
+				if next.used[name] {
+					return g.errorf(next.pos,
+						"variable %s is used in a different step than declared, declare it with var and explicit type", name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) checkControlFlow(stmt ast.Stmt) error {
+	var err error
+	var inspect func(node ast.Node, breakable, continuable bool) bool
+	inspect = func(node ast.Node, breakable, continuable bool) bool {
+		if err != nil {
+			return false
+		}
+
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			err = g.errorf(n.Pos(), "return statements are not supported")
+			return false
+		case *ast.BranchStmt:
+			switch {
+			case n.Label != nil || n.Tok == token.GOTO:
+				err = g.errorf(n.Pos(), "labeled branch statements are not supported")
+			case n.Tok == token.BREAK && !breakable, n.Tok == token.CONTINUE && !continuable:
+				err = g.errorf(n.Pos(), "%s outside of step is not supported", n.Tok)
+			}
+			return false
+		case *ast.ForStmt, *ast.RangeStmt:
+			for _, child := range children(n) {
+				ast.Inspect(child, func(node ast.Node) bool { return inspect(node, true, true) })
+			}
+			return false
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			for _, child := range children(n) {
+				ast.Inspect(child, func(node ast.Node) bool { return inspect(node, true, continuable) })
+			}
+			return false
+		}
+		return true
+	}
+
+	ast.Inspect(stmt, func(node ast.Node) bool { return inspect(node, false, false) })
+	return err
+}
+
+func children(node ast.Node) []ast.Node {
+	switch n := node.(type) {
+	case *ast.ForStmt:
+		return []ast.Node{n.Body}
+	case *ast.RangeStmt:
+		return []ast.Node{n.Body}
+	case *ast.SwitchStmt:
+		return []ast.Node{n.Body}
+	case *ast.TypeSwitchStmt:
+		return []ast.Node{n.Body}
+	case *ast.SelectStmt:
+		return []ast.Node{n.Body}
+	}
+	return nil
+}
+
+func (g *generator) hasWaits(node ast.Node) bool {
+	found := false
+	ast.Inspect(node, func(node ast.Node) bool {
+		if found {
+			return false
+		}
+		if call, ok := node.(*ast.CallExpr); ok {
+			_, found = g.waiter(call)
+		}
+		return !found
+	})
+	return found
+}
+
+func (g *generator) waiter(call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !isIdent(sel.X, g.routines) {
+		return "", false
+	}
+
+	waiter, ok := waiters[sel.Sel.Name]
+	return waiter, ok
+}
+
+func (g *generator) node(node ast.Node) string {
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, g.fset, node); err != nil {
+		panic(fmt.Errorf("print node: %w", err))
+	}
+	return b.String()
+}
+
+func (g *generator) field(field *ast.Field) string {
+	var names []string
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	if len(names) == 0 {
+		return g.node(field.Type)
+	}
+	return strings.Join(names, ", ") + " " + g.node(field.Type)
+}
+
+func (g *generator) errorf(pos token.Pos, format string, args ...any) error {
+	return fmt.Errorf("%s: %s", g.fset.Position(pos), fmt.Sprintf(format, args...))
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func describe(stmt ast.Stmt) string {
+	switch stmt.(type) {
+	case *ast.IfStmt:
+		return "if statements"
+	case *ast.SwitchStmt, *ast.TypeSwitchStmt:
+		return "switch statements"
+	case *ast.SelectStmt:
+		return "select statements"
+	case *ast.GoStmt:
+		return "go statements"
+	case *ast.DeferStmt:
+		return "defer statements"
+	case *ast.LabeledStmt:
+		return "labeled statements"
+	}
+	return "expressions"
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, name := range []string{"loader", "counter"} {
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile("testdata/" + name + ".go")
+			test.Equal(t, err, nil)
+
+			expected, err := os.ReadFile("testdata/" + name + ".golden")
+			test.Equal(t, err, nil)
+
+			actual, err := generate("testdata/"+name+".go", src)
+			test.Equal(t, err, nil)
+			test.Equal(t, string(actual), string(expected))
+		})
+	}
+}
+
+// Golden files are built together with their sources in a temporary module and their tests are run
+func TestGenerate_Run(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated code")
+	}
+
+	root, err := filepath.Abs("../..")
+	test.Equal(t, err, nil)
+
+	dir := t.TempDir()
+	mod := "module golden\n\ngo 1.23\n\nrequire github.com/mymmrac/routines v0.0.0\n\n" +
+		"replace github.com/mymmrac/routines => " + root + "\n"
+	test.Equal(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o644), nil)
+
+	files := map[string]string{
+		"loader.go":       "loader/loader.go",
+		"loader.golden":   "loader/loader_routines.go",
+		"counter.go":      "counter/counter.go",
+		"counter.golden":  "counter/counter_routines.go",
+		"counter_test.go": "counter/counter_test.go",
+	}
+	for from, to := range files {
+		data, err := os.ReadFile(filepath.Join("testdata", from))
+		test.Equal(t, err, nil)
+		test.Equal(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(to)), 0o755), nil)
+		test.Equal(t, os.WriteFile(filepath.Join(dir, to), data, 0o644), nil)
+	}
+
+	for _, args := range [][]string{{"vet", "./..."}, {"test", "-count=1", "./..."}} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s: %s\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+func TestGenerate_NotMarked(t *testing.T) {
+	actual, err := generate("main.go", []byte("package main\n\nfunc main() {}\n"))
+	test.Equal(t, err, nil)
+	test.True(t, actual == nil)
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  string
+	}{
+		{
+			name: "results",
+			body: "func f() int {\n\treturn 1\n}",
+			err:  "functions with results are not supported",
+		},
+		{
+			name: "if",
+			body: "func f(ok bool) {\n\tif ok {\n\t\troutines.Sleep(time.Second)\n\t}\n}",
+			err:  "waits inside if statements are not supported",
+		},
+		{
+			name: "loop",
+			body: "func f() {\n\tfor i := 0; i < 3; i += 2 {\n\t\troutines.Sleep(time.Second)\n\t}\n}",
+			err:  "loops with waits must have form",
+		},
+		{
+			name: "range",
+			body: "func f(s []int) {\n\tfor _, v := range s {\n\t\troutines.Sleep(time.Duration(v))\n\t}\n}",
+			err:  "range loops with waits must have form",
+		},
+		{
+			name: "range-unknown",
+			body: "func f(s interface{ Len() int }) {\n\tfor i := range s.Len() {\n\t\troutines.Sleep(time.Duration(i))\n\t}\n}",
+			err:  "type of s.Len() is unknown",
+		},
+		{
+			name: "range-map",
+			body: "func f(m map[string]int) {\n\tfor k := range m {\n\t\troutines.Sleep(time.Duration(len(k)))\n\t}\n}",
+			err:  "range loops with waits over map keys are not supported",
+		},
+		{
+			name: "return",
+			body: "func f(ok bool) {\n\tif ok {\n\t\treturn\n\t}\n\troutines.Sleep(time.Second)\n}",
+			err:  "return statements are not supported",
+		},
+		{
+			name: "break",
+			body: "func f() {\n\tfor i := 0; i < 3; i++ {\n\t\tif i == 1 {\n\t\t\tbreak\n\t\t}\n\t\troutines.Sleep(time.Second)\n\t}\n}",
+			err:  "break outside of step is not supported",
+		},
+		{
+			name: "shared-variable",
+			body: "func f() {\n\tx := 1\n\troutines.Sleep(time.Second)\n\tprintln(x)\n}",
+			err:  "variable x is used in a different step than declared",
+		},
+		{
+			name: "redeclared-variable",
+			body: "func f() {\n\tvar x int\n\troutines.Sleep(time.Second)\n\t{\n\t\tvar x int\n\t\troutines.Sleep(time.Second)\n\t\tprintln(x)\n\t}\n}",
+			err:  "variable x is declared more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "package p\n\nimport (\n\t\"time\"\n\n\t\"github.com/mymmrac/routines\"\n)\n\n" +
+				"var _ = time.Second\nvar _ = routines.Sleep\n\n//routines:generate\n" + tt.body + "\n"
+
+			_, err := generate("p.go", []byte(src))
+			test.True(t, err != nil)
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("%q does not contain %q", err.Error(), tt.err)
+			}
+		})
+	}
+}
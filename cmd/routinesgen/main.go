@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	input := flag.String("file", os.Getenv("GOFILE"), "file with functions marked by "+directive)
+	output := flag.String("output", "", "output file (default: <file>_routines.go)")
+	flag.Parse()
+
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "routinesgen: no input file, use -file or run with go generate")
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(*input, ".go") + "_routines.go"
+	}
+
+	src, err := os.ReadFile(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "routinesgen: %s\n", err)
+		os.Exit(1)
+	}
+
+	code, err := generate(*input, src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "routinesgen: %s\n", err)
+		os.Exit(1)
+	}
+	if code == nil {
+		fmt.Fprintf(os.Stderr, "routinesgen: no functions marked by %s in %s\n", directive, *input)
+		os.Exit(1)
+	}
+
+	if err = os.WriteFile(*output, code, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "routinesgen: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,24 @@
+package counter
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/mymmrac/routines"
+)
+
+const pause = time.Millisecond
+
+//routines:generate
+func count(words []string, rounds int, out *[]string) {
+	var total int
+	for i := range words {
+		routines.Sleep(pause)
+		total += len(words[i])
+	}
+	for range rounds {
+		routines.Sleep(pause)
+		total++
+	}
+	*out = append(*out, strconv.Itoa(total))
+}
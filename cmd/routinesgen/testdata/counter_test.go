@@ -0,0 +1,25 @@
+package counter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mymmrac/routines"
+)
+
+func TestCount(t *testing.T) {
+	var out []string
+	r := routines.StartRoutine()
+	tick := countRoutine(r, []string{"ab", "cdef", "gh"}, 2, &out)
+
+	for range 2 {
+		for !r.Completed() {
+			tick()
+		}
+		r.Restart()
+	}
+
+	if !slices.Equal(out, []string{"10", "10"}) {
+		t.Fatalf("unexpected output: %v", out)
+	}
+}
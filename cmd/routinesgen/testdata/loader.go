@@ -0,0 +1,35 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mymmrac/routines"
+)
+
+//go:generate go run github.com/mymmrac/routines/cmd/routinesgen
+
+//routines:generate
+func load(name string, steps int, ready func() bool) {
+	var dots strings.Builder
+	fmt.Println("Loading", name)
+	for i := 0; i < steps; i++ {
+		routines.Sleep(time.Second / 2)
+		dots.WriteString(".")
+		fmt.Print(".")
+	}
+	routines.SleepUntil(ready)
+	fmt.Println()
+	fmt.Println("Done!", dots.Len())
+	for range 2 {
+		var n int = steps
+		routines.SleepUntilOrTimeout(ready, time.Second)
+		n++
+		fmt.Println(n)
+	}
+}
+
+func notMarked() {
+	routines.Sleep(time.Second)
+}
@@ -0,0 +1,241 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const routinesPath = "github.com/mymmrac/routines"
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "routinesvet",
+	Doc:      "check for routine steps whose caller based identity is not stable between ticks",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// Methods and functions that identify themselves by caller, so they must be called from the same place on each tick
+var steps = map[string]bool{
+	"Do":                        true,
+	"Func":                      true,
+	"Loop":                      true,
+	"Repeat":                    true,
+	"ForEach":                   true,
+	"ForEach2":                  true,
+	"Yield":                     true,
+	"Retry":                     true,
+	"WithTimeout":               true,
+	"Every":                     true,
+	"EveryUntil":                true,
+	"Interval":                  true,
+	"IntervalUntil":             true,
+	"Choose":                    true,
+	"WaitFor":                   true,
+	"WaitForAligned":            true,
+	"WaitForRandom":             true,
+	"WaitUntilTime":             true,
+	"WaitForSchedule":           true,
+	"WaitUntil":                 true,
+	"WaitUntilOrTimeout":        true,
+	"WaitUntilEvery":            true,
+	"WaitUntilEveryOrTimeout":   true,
+	"WaitUntilBackoff":          true,
+	"WaitUntilBackoffOrTimeout": true,
+	"WaitForDone":               true,
+	"WaitForDoneOrTimeout":      true,
+	"WaitForAll":                true,
+	"WaitForAllOrTimeout":       true,
+	"WaitForAny":                true,
+	"WaitForAnyOrTimeout":       true,
+	"WaitForQuorum":             true,
+	"WaitForQuorumOrTimeout":    true,
+	"WaitForChange":             true,
+	"WaitForStable":             true,
+	"WaitForCount":              true,
+	"Select":                    true,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if pass.Pkg.Path() == routinesPath {
+		return nil, nil
+	}
+
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	helpers := make(map[types.Object]bool)
+	helperCalls := make(map[types.Object][]*ast.CallExpr)
+
+	ins.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(node ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		call := node.(*ast.CallExpr)
+
+		if !isStep(pass, call) {
+			if helper := calledFunc(pass, call); helper != nil && !isWrapped(pass, stack) {
+				helperCalls[helper] = append(helperCalls[helper], call)
+			}
+			return true
+		}
+
+		if helper := enclosingHelper(pass, stack); helper != nil {
+			helpers[helper] = true
+		}
+
+		for i := len(stack) - 2; i >= 0; i-- {
+			switch n := stack[i].(type) {
+			case *ast.FuncLit, *ast.FuncDecl:
+				return true
+			case *ast.ForStmt:
+				if isTickLoop(pass, n.Cond, n.Body) {
+					return true
+				}
+				pass.Reportf(call.Pos(), "routine step called inside for loop, use Loop or Repeat instead")
+				return true
+			case *ast.RangeStmt:
+				if isTickLoop(pass, nil, n.Body) {
+					return true
+				}
+				pass.Reportf(call.Pos(), "routine step called inside range loop, use Loop, Repeat or ForEach instead")
+				return true
+			case *ast.IfStmt:
+				if inBlock(stack[i+1], n.Body, n.Else) {
+					pass.Reportf(call.Pos(), "routine step called conditionally, condition must not change between ticks, wrap branch in Func")
+					return true
+				}
+			case *ast.CaseClause, *ast.CommClause:
+				pass.Reportf(call.Pos(), "routine step called conditionally, condition must not change between ticks, wrap branch in Func")
+				return true
+			}
+		}
+		return true
+	})
+
+	for helper, calls := range helperCalls {
+		if !helpers[helper] || len(calls) < 2 {
+			continue
+		}
+		for _, call := range calls {
+			pass.Reportf(call.Pos(), "%s contains routine steps and is called from several places, wrap each call in Func", helper.Name())
+		}
+	}
+
+	return nil, nil
+}
+
+func isStep(pass *analysis.Pass, call *ast.CallExpr) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != routinesPath {
+		return false
+	}
+	return steps[fn.Name()]
+}
+
+func calledFunc(pass *analysis.Pass, call *ast.CallExpr) types.Object {
+	switch obj := typeutil.Callee(pass.TypesInfo, call).(type) {
+	case *types.Func:
+		if obj.Pkg() == pass.Pkg {
+			return obj
+		}
+	case *types.Var:
+		if obj.Pkg() == pass.Pkg {
+			return obj
+		}
+	}
+	return nil
+}
+
+// Wrapped calls are made from a closure passed to a routine step, so the step pushes its own caller to the stack
+func isWrapped(pass *analysis.Pass, stack []ast.Node) bool {
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.FuncDecl:
+			return false
+		case *ast.FuncLit:
+			if i == 0 {
+				return false
+			}
+			call, ok := stack[i-1].(*ast.CallExpr)
+			return ok && isStep(pass, call)
+		}
+	}
+	return false
+}
+
+func enclosingHelper(pass *analysis.Pass, stack []ast.Node) types.Object {
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.FuncLit:
+			if i == 0 {
+				return nil
+			}
+			switch parent := stack[i-1].(type) {
+			case *ast.AssignStmt:
+				for j, rhs := range parent.Rhs {
+					if rhs == n && j < len(parent.Lhs) {
+						if id, ok := parent.Lhs[j].(*ast.Ident); ok {
+							return pass.TypesInfo.ObjectOf(id)
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				for j, value := range parent.Values {
+					if value == n && j < len(parent.Names) {
+						return pass.TypesInfo.ObjectOf(parent.Names[j])
+					}
+				}
+			}
+			return nil
+		case *ast.FuncDecl:
+			return pass.TypesInfo.ObjectOf(n.Name)
+		}
+	}
+	return nil
+}
+
+// Tick loop is the outer loop that drives routine, it checks for completion or ends routine on each iteration
+func isTickLoop(pass *analysis.Pass, cond ast.Expr, body *ast.BlockStmt) bool {
+	tick := false
+	check := func(node ast.Node) bool {
+		if tick {
+			return false
+		}
+		if _, ok := node.(*ast.FuncLit); ok {
+			return false
+		}
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if ok && fn.Pkg() != nil && fn.Pkg().Path() == routinesPath {
+			switch fn.Name() {
+			case "Completed", "Done", "End", "Tick", "Next":
+				tick = true
+			}
+		}
+		return true
+	}
+
+	if cond != nil {
+		ast.Inspect(cond, check)
+	}
+	for _, stmt := range body.List {
+		ast.Inspect(stmt, check)
+	}
+	return tick
+}
+
+func inBlock(node ast.Node, blocks ...ast.Node) bool {
+	for _, block := range blocks {
+		if block != nil && node == block {
+			return true
+		}
+	}
+	return false
+}
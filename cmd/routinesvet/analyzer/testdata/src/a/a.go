@@ -0,0 +1,111 @@
+package a
+
+import (
+	"slices"
+	"time"
+
+	"github.com/mymmrac/routines"
+)
+
+func helper(r *routines.Routine) {
+	r.Do(func() {})
+	r.WaitFor(time.Second)
+}
+
+func single(r *routines.Routine) {
+	r.WaitFor(time.Second)
+}
+
+func ok() {
+	r := routines.StartRoutine()
+	for !r.Completed() {
+		r.Do(func() {})
+		r.Loop(0, 3, func(i int) {
+			r.WaitFor(time.Second)
+		})
+		routines.ForEach(r, slices.Values([]int{1, 2}), func(v int) {
+			r.Do(func() {})
+		})
+		r.Func(func() {
+			helper(r)
+		})
+		r.Func(func() {
+			helper(r)
+		})
+		single(r)
+		r.End()
+	}
+}
+
+func done() {
+	r := routines.StartRoutine()
+	for !r.Done() {
+		r.Do(func() {})
+		r.WaitFor(time.Second)
+	}
+}
+
+func loops(values []int) {
+	r := routines.StartRoutine()
+	for {
+		for i := 0; i < 3; i++ {
+			r.Do(func() {}) // want `routine step called inside for loop, use Loop or Repeat instead`
+		}
+		for range values {
+			r.WaitFor(time.Second) // want `routine step called inside range loop, use Loop, Repeat or ForEach instead`
+		}
+		r.Do(func() {
+			for range 2 {
+				r.Do(func() {}) // want `routine step called inside range loop`
+			}
+		})
+		r.End()
+	}
+}
+
+func others(values []int, m *routines.Metrics) {
+	r := routines.StartRoutine()
+	for !r.Completed() {
+		for range values {
+			m.Snapshot()
+			_ = r.Progress()
+			routines.WaitForChange(r, func() int { return 0 }) // want `routine step called inside range loop`
+		}
+		r.End()
+	}
+}
+
+func conditions(flag bool, n int) {
+	r := routines.StartRoutine()
+	for !r.Completed() {
+		if flag {
+			r.Do(func() {}) // want `routine step called conditionally`
+		} else if n > 0 {
+			r.WaitFor(time.Second) // want `routine step called conditionally`
+		}
+		switch n {
+		case 1:
+			r.Do(func() {}) // want `routine step called conditionally`
+		}
+		if flag {
+			r.Func(func() { // want `routine step called conditionally`
+				r.Do(func() {})
+			})
+		}
+		r.End()
+	}
+}
+
+func helpers() {
+	r := routines.StartRoutine()
+	local := func() {
+		r.Do(func() {})
+	}
+	for !r.Completed() {
+		helper(r) // want `helper contains routine steps and is called from several places, wrap each call in Func`
+		helper(r) // want `helper contains routine steps and is called from several places`
+		local()   // want `local contains routine steps`
+		local()   // want `local contains routine steps`
+		r.End()
+	}
+}
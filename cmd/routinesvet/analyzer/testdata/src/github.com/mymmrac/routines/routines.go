@@ -0,0 +1,29 @@
+package routines
+
+import (
+	"iter"
+	"time"
+)
+
+type Routine struct{}
+
+func StartRoutine() *Routine { return &Routine{} }
+
+func (r *Routine) Start()                                  {}
+func (r *Routine) End()                                    {}
+func (r *Routine) Completed() bool                         { return false }
+func (r *Routine) Done() bool                              { return false }
+func (r *Routine) Progress() float64                       { return 0 }
+func (r *Routine) Do(action func())                        {}
+func (r *Routine) Func(action func())                      {}
+func (r *Routine) Loop(start, end int, action func(i int)) {}
+func (r *Routine) Repeat(n int, action func())             {}
+func (r *Routine) WaitFor(duration time.Duration)          {}
+
+func ForEach[T any](r *Routine, seq iter.Seq[T], action func(v T)) {}
+
+func WaitForChange[T comparable](r *Routine, value func() T) {}
+
+type Metrics struct{}
+
+func (m *Metrics) Snapshot() {}
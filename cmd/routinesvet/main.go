@@ -0,0 +1,11 @@
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/mymmrac/routines/cmd/routinesvet/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
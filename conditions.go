@@ -0,0 +1,112 @@
+package routines
+
+import "time"
+
+// WaitForChange waits for value to differ from value it had when wait was reached
+func WaitForChange[T comparable](r *Routine, value func() T) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepWaitForChange)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	r.addExecution(caller)
+	r.run(caller, s, func() {
+		initial := executionValues(r, caller, value)
+		if r.consumeSkipCondition() || value() != initial {
+			r.markAsExecuted(caller)
+			r.finish(s)
+		}
+	})
+}
+
+// WaitForStable waits for condition to be true continuously for duration, timer restarts each time condition is false
+func (r *Routine) WaitForStable(condition func() bool, duration time.Duration) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepWaitForStable)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	r.addExecution(caller)
+	r.run(caller, s, func() {
+		if r.consumeSkipCondition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+			return
+		}
+
+		if !condition() {
+			delete(r.timers, caller)
+			return
+		}
+
+		deadline := r.executionTimer(caller, duration)
+		if r.isExpired(deadline) {
+			r.markAsExecuted(caller)
+			r.finish(s)
+		}
+	})
+}
+
+// WaitForCount waits for condition to be observed true n times, condition is checked once per tick
+func (r *Routine) WaitForCount(condition func() bool, n int) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepWaitForCount)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	r.addExecution(caller)
+	r.run(caller, s, func() {
+		count := executionValues(r, caller, func() *int {
+			return new(int)
+		})
+		if r.consumeSkipCondition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+			return
+		}
+
+		if *count < n && condition() {
+			*count++
+		}
+		if *count >= n {
+			r.markAsExecuted(caller)
+			r.finish(s)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package routines_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestWaitForChange(t *testing.T) {
+	r := routines.StartRoutine(routines.WithSteps())
+
+	state, e1 := "idle", 0
+	for i := 0; i < 6; i++ {
+		routines.WaitForChange(r, func() string {
+			return state
+		})
+		r.Do(func() {
+			e1++
+		})
+		r.End()
+
+		if i == 3 {
+			test.Equal(t, e1, 0)
+			state = "running"
+		}
+	}
+
+	test.Equal(t, e1, 1)
+	test.True(t, r.Completed())
+	test.Equal(t, r.Steps()[1].Kind, routines.StepWaitForChange)
+}
+
+func TestRoutine_WaitForStable(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	r := routines.StartRoutine(routines.WithClock(clock))
+
+	pressed := []bool{true, true, false, true, true, true}
+	e1 := 0
+	for i := 0; i < len(pressed); i++ {
+		r.WaitForStable(func() bool {
+			return pressed[i]
+		}, time.Second*2)
+		r.Do(func() {
+			e1++
+		})
+		r.End()
+
+		if i < len(pressed)-1 {
+			test.Equal(t, e1, 0)
+		}
+		clock.now = clock.now.Add(time.Second)
+	}
+
+	test.Equal(t, e1, 1)
+	test.True(t, r.Completed())
+}
+
+func TestRoutine_WaitForCount(t *testing.T) {
+	r := routines.StartRoutine()
+
+	values := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	e1, last := 0, 0
+	for _, v := range values {
+		r.WaitForCount(func() bool {
+			return v%2 == 0
+		}, 3)
+		r.Do(func() {
+			e1++
+			last = v
+		})
+		r.End()
+	}
+
+	test.Equal(t, e1, 1)
+	test.Equal(t, last, 6)
+	test.True(t, r.Completed())
+}
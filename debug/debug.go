@@ -0,0 +1,299 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mymmrac/routines"
+)
+
+type StepState struct {
+	Kind      string        `json:"kind"`
+	File      string        `json:"file"`
+	Line      int           `json:"line"`
+	Status    string        `json:"status"`
+	Deadline  *time.Time    `json:"deadline,omitempty"`
+	Remaining time.Duration `json:"remaining_ns,omitempty"`
+}
+
+type RoutineState struct {
+	Name      string     `json:"name"`
+	Started   bool       `json:"started"`
+	Completed bool       `json:"completed"`
+	Paused    bool       `json:"paused"`
+	Error     string     `json:"error,omitempty"`
+	Progress  float64    `json:"progress"`
+	Current   *StepState `json:"current,omitempty"`
+}
+
+type entry struct {
+	routine  *routines.Routine
+	state    RoutineState
+	commands []func(r *routines.Routine)
+	detach   func()
+}
+
+// Registry keeps routines available for debugging, routines are never accessed outside of their own ticks,
+// state is captured and commands are applied by tick hooks, so completed or failed routine is handled by its End
+type Registry struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: make(map[string]*entry),
+	}
+}
+
+func Register(routine *routines.Routine) {
+	DefaultRegistry.Register(routine)
+}
+
+func Unregister(routine *routines.Routine) {
+	DefaultRegistry.Unregister(routine)
+}
+
+func Handler() http.Handler {
+	return DefaultRegistry
+}
+
+// Register adds routine to the registry, it must be called from the goroutine that runs routine
+func (reg *Registry) Register(routine *routines.Routine) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	base := routine.Name()
+	if base == "" {
+		base = fmt.Sprintf("routine-%d", len(reg.entries)+1)
+	}
+	name := base
+	for i := 2; reg.entries[name] != nil; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	// Steps of registered routine are recorded, so its current step can be shown
+	routines.WithSteps()(routine)
+
+	e := &entry{
+		routine: routine,
+		state:   capture(name, routine),
+	}
+	reg.entries[name] = e
+
+	e.detach = routine.OnTick(func() {
+		reg.tick(name, e)
+	})
+}
+
+// Unregister removes routine from the registry, it must be called from the goroutine that runs routine
+func (reg *Registry) Unregister(routine *routines.Routine) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	for name, e := range reg.entries {
+		if e.routine == routine {
+			e.detach()
+			delete(reg.entries, name)
+		}
+	}
+}
+
+func (reg *Registry) tick(name string, e *entry) {
+	reg.mu.Lock()
+	if reg.entries[name] != e {
+		reg.mu.Unlock()
+		return
+	}
+	commands := e.commands
+	e.commands = nil
+	reg.mu.Unlock()
+
+	for _, command := range commands {
+		command(e.routine)
+	}
+	state := capture(name, e.routine)
+
+	reg.mu.Lock()
+	e.state = state
+	reg.mu.Unlock()
+}
+
+func (reg *Registry) States() []RoutineState {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	states := make([]RoutineState, 0, len(reg.entries))
+	for _, e := range reg.entries {
+		states = append(states, e.state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states
+}
+
+func capture(name string, routine *routines.Routine) RoutineState {
+	state := RoutineState{
+		Name:      name,
+		Started:   routine.Started(),
+		Completed: routine.Completed(),
+		Paused:    routine.Paused(),
+		Progress:  routine.Progress(),
+	}
+	if err := routine.Err(); err != nil {
+		state.Error = err.Error()
+	}
+
+	steps := routine.Steps()
+	parents := make(map[int]bool)
+	for _, step := range steps {
+		if step.Status == routines.StatusWaiting {
+			parents[step.Parent] = true
+		}
+	}
+	for _, step := range steps {
+		if step.Status != routines.StatusWaiting || parents[step.ID] {
+			continue
+		}
+
+		current := &StepState{
+			Kind:      step.Kind.String(),
+			File:      step.File,
+			Line:      step.Line,
+			Status:    step.Status.String(),
+			Remaining: step.Remaining,
+		}
+		if !step.Deadline.IsZero() {
+			deadline := step.Deadline
+			current.Deadline = &deadline
+		}
+		state.Current = current
+		break
+	}
+
+	return state
+}
+
+var commands = map[string]func(r *routines.Routine){
+	"pause":   (*routines.Routine).Pause,
+	"resume":  (*routines.Routine).Resume,
+	"skip":    (*routines.Routine).SkipWait,
+	"restart": (*routines.Routine).Restart,
+}
+
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	action := strings.Trim(req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:], "/")
+	switch action {
+	case "":
+		reg.serveIndex(w, req)
+	case "json":
+		reg.serveJSON(w, req)
+	default:
+		reg.serveCommand(w, req, action)
+	}
+}
+
+func (reg *Registry) serveJSON(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(reg.States())
+}
+
+func (reg *Registry) serveCommand(w http.ResponseWriter, req *http.Request, action string) {
+	command, ok := commands[action]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := req.FormValue("name")
+
+	reg.mu.Lock()
+	e, found := reg.entries[name]
+	if found {
+		e.commands = append(e.commands, command)
+	}
+	reg.mu.Unlock()
+
+	if !found {
+		http.Error(w, fmt.Sprintf("routine %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	if req.FormValue("redirect") != "" {
+		http.Redirect(w, req, "./", http.StatusSeeOther)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+var index = template.Must(template.New("index").Funcs(template.FuncMap{
+	"base": filepath.Base,
+	"percent": func(progress float64) string {
+		return fmt.Sprintf("%.0f%%", progress*100)
+	},
+	"round": func(d time.Duration) time.Duration {
+		return d.Round(time.Millisecond)
+	},
+	"actions": func() []string {
+		return []string{"pause", "resume", "skip", "restart"}
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><title>Routines</title></head>
+<body>
+<h1>Routines</h1>
+<table border="1" cellpadding="4">
+<tr><th>Name</th><th>State</th><th>Progress</th><th>Current step</th><th>Remaining</th><th>Actions</th></tr>
+{{- range .}}
+<tr>
+<td>{{.Name}}</td>
+<td>{{if .Completed}}completed{{else if .Error}}failed: {{.Error}}{{else if .Paused}}paused{{else if .Started}}running{{else}}not started{{end}}</td>
+<td>{{percent .Progress}}</td>
+<td>{{with .Current}}{{.Kind}} at <span title="{{.File}}">{{base .File}}:{{.Line}}</span>{{end}}</td>
+<td>{{with .Current}}{{if .Deadline}}{{round .Remaining}}{{end}}{{end}}</td>
+<td>
+{{- $name := .Name}}
+{{- range actions}}
+<form method="post" action="{{.}}" style="display:inline">
+<input type="hidden" name="name" value="{{$name}}"><input type="hidden" name="redirect" value="1">
+<button type="submit">{{.}}</button>
+</form>
+{{- end}}
+</td>
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+func (reg *Registry) serveIndex(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := index.Execute(w, reg.States()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
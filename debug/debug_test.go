@@ -0,0 +1,162 @@
+package debug_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/debug"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := debug.NewRegistry()
+	r := routines.StartRoutine(routines.WithName("worker"))
+	registry.Register(r)
+
+	e1 := 0
+	tick := func() {
+		r.WaitFor(time.Hour)
+		r.Do(func() {
+			e1++
+		})
+		r.End()
+	}
+
+	states := func() []debug.RoutineState {
+		rec := httptest.NewRecorder()
+		registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routines/json", nil))
+		test.Equal(t, rec.Code, http.StatusOK)
+
+		var states []debug.RoutineState
+		test.Equal(t, json.NewDecoder(rec.Body).Decode(&states), nil)
+		return states
+	}
+
+	command := func(action, name string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/debug/routines/"+action+"?name="+name, nil)
+		registry.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tick()
+
+	s := states()
+	test.Equal(t, len(s), 1)
+	test.Equal(t, s[0].Name, "worker")
+	test.True(t, s[0].Started)
+	test.False(t, s[0].Paused)
+	test.Equal(t, s[0].Current.Kind, "WaitFor")
+	test.True(t, strings.HasSuffix(s[0].Current.File, "debug_test.go"))
+	test.True(t, s[0].Current.Deadline != nil)
+	test.True(t, s[0].Current.Remaining > time.Minute)
+
+	test.Equal(t, command("pause", "worker"), http.StatusAccepted)
+	test.Equal(t, command("skip", "worker"), http.StatusAccepted)
+	test.Equal(t, command("skip", "unknown"), http.StatusNotFound)
+	test.Equal(t, command("unknown", "worker"), http.StatusNotFound)
+
+	tick()
+	test.True(t, r.Paused())
+	test.True(t, states()[0].Paused)
+
+	tick()
+	test.Equal(t, e1, 0)
+
+	test.Equal(t, command("resume", "worker"), http.StatusAccepted)
+	tick()
+	test.Equal(t, command("restart", "worker"), http.StatusAccepted)
+	tick()
+	test.True(t, r.Started())
+	test.False(t, r.Paused())
+
+	tick()
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routines/", nil))
+	test.Equal(t, rec.Code, http.StatusOK)
+	test.True(t, strings.Contains(rec.Body.String(), "<td>worker</td>"))
+	test.True(t, strings.Contains(rec.Body.String(), "WaitFor at"))
+
+	test.Equal(t, command("skip", "worker"), http.StatusAccepted)
+	tick()
+	tick()
+	test.Equal(t, e1, 1)
+	test.True(t, r.Completed())
+	test.True(t, states()[0].Completed)
+
+	test.Equal(t, command("restart", "worker"), http.StatusAccepted)
+	tick()
+	test.True(t, r.Started())
+	test.False(t, r.Completed())
+	test.True(t, states()[0].Started)
+
+	r.Fail(errors.New("failed"))
+	tick()
+	test.Equal(t, states()[0].Error, "failed")
+
+	test.Equal(t, command("restart", "worker"), http.StatusAccepted)
+	tick()
+	test.True(t, r.Started())
+	test.False(t, r.Failed())
+	test.Equal(t, states()[0].Error, "")
+
+	rec = httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routines/pause?name=worker", nil))
+	test.Equal(t, rec.Code, http.StatusMethodNotAllowed)
+
+	registry.Unregister(r)
+	test.Equal(t, len(states()), 0)
+
+	r1, r2 := routines.NewRoutine(), routines.NewRoutine()
+	registry.Register(r1)
+	registry.Register(r2)
+	registry.Unregister(r1)
+	registry.Register(routines.NewRoutine())
+
+	s = states()
+	test.Equal(t, len(s), 2)
+	test.Equal(t, s[0].Name, "routine-2")
+	test.Equal(t, s[1].Name, "routine-2-2")
+}
+
+func TestHandler_Concurrent(t *testing.T) {
+	r := routines.StartRoutine(routines.WithName("concurrent"))
+	debug.Register(r)
+	defer debug.Unregister(r)
+
+	server := httptest.NewServer(debug.Handler())
+	defer server.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for !r.Completed() {
+			r.WaitFor(time.Hour)
+			r.End()
+		}
+	}()
+
+	for {
+		resp, err := http.Get(server.URL + "/json")
+		test.Equal(t, err, nil)
+		test.Equal(t, resp.Body.Close(), nil)
+
+		resp, err = http.Post(server.URL+"/skip?name=concurrent", "", nil)
+		test.Equal(t, err, nil)
+		test.Equal(t, resp.Body.Close(), nil)
+		test.Equal(t, resp.StatusCode, http.StatusAccepted)
+
+		<-time.After(time.Millisecond)
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
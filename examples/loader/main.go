@@ -9,13 +9,13 @@ import (
 
 func main() {
 	r := routines.StartRoutine()
-	for !r.Completed() {
+	for !r.Done() {
 		r.Do(func() {
 			fmt.Println("Hello Routines!")
 			fmt.Print("Loading")
 		})
 		r.Repeat(3, func() {
-			r.WaitFor(time.Second / 2)
+			r.WaitForAligned(time.Second / 2)
 			r.Do(func() {
 				fmt.Print(".")
 			})
@@ -0,0 +1,96 @@
+package routines
+
+import (
+	"iter"
+	"time"
+)
+
+type Generator[T any] struct {
+	*Routine
+	body    func(g *Generator[T])
+	value   T
+	yielded bool
+}
+
+func NewGenerator[T any](body func(g *Generator[T])) *Generator[T] {
+	return &Generator[T]{
+		// Steps are used to find out how long generator is idle between ticks
+		Routine: NewRoutine(WithSteps()),
+		body:    body,
+	}
+}
+
+func (g *Generator[T]) Yield(value T) {
+	if !g.running() {
+		return
+	}
+
+	caller, pop := g.pushToStack(g.caller())
+	defer pop()
+
+	if g.isExecuted(caller) {
+		return
+	}
+	s := g.step(caller, StepYield)
+	if !g.isPrevExecuted(caller) {
+		g.skip(s)
+		return
+	}
+	g.reach(s)
+	g.addExecution(caller)
+	g.markAsExecuted(caller)
+
+	g.run(caller, s, func() {
+		g.value = value
+		g.yielded = true
+		g.suspended = true
+	})
+	g.finish(s)
+}
+
+func (g *Generator[T]) Tick() (T, bool) {
+	var zero T
+	if g.Done() {
+		return zero, false
+	}
+
+	g.yielded = false
+	g.suspended = false
+
+	g.Start()
+	g.body(g)
+	g.End()
+
+	g.suspended = false
+	if !g.yielded {
+		return zero, false
+	}
+
+	value := g.value
+	g.value = zero
+	return value, true
+}
+
+// Next sleeps between ticks until the closest deadline of pending waits, so timed waits don't spin
+func (g *Generator[T]) Next() (T, bool) {
+	for !g.Done() {
+		if value, ok := g.Tick(); ok {
+			return value, true
+		}
+		time.Sleep(g.idleDuration())
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (g *Generator[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			value, ok := g.Next()
+			if !ok || !yield(value) {
+				return
+			}
+		}
+	}
+}
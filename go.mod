@@ -1,3 +1,3 @@
 module github.com/mymmrac/routines
 
-go 1.20
+go 1.23
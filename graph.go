@@ -0,0 +1,195 @@
+package routines
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Graph struct {
+	Steps []StepInfo
+}
+
+// Graph returns graph of known steps, steps are recorded after the first call like for Steps
+func (r *Routine) Graph() *Graph {
+	r.recordSteps = true
+	return &Graph{
+		Steps: r.stepInfos(),
+	}
+}
+
+// GraphFromTrace restores graph from recorded events, only events after the last reset are used
+func GraphFromTrace(events []Event) *Graph {
+	steps := make(map[int]*StepInfo)
+	for _, event := range events {
+		if event.Kind == EventReset {
+			steps = make(map[int]*StepInfo)
+			continue
+		}
+		if event.ID == 0 {
+			continue
+		}
+
+		info, found := steps[event.ID]
+		if !found {
+			info = &StepInfo{
+				ID:     event.ID,
+				Parent: event.Parent,
+				Kind:   event.Step,
+				File:   event.File,
+				Line:   event.Line,
+			}
+			steps[event.ID] = info
+		}
+
+		switch event.Kind {
+		case EventReached, EventWaitStarted:
+			if info.Status == StatusPending {
+				info.Status = StatusWaiting
+			}
+		case EventExecuted, EventWaitFinished:
+			info.Status = StatusDone
+		}
+	}
+
+	graph := &Graph{
+		Steps: make([]StepInfo, 0, len(steps)),
+	}
+	for _, info := range steps {
+		graph.Steps = append(graph.Steps, *info)
+	}
+	sort.Slice(graph.Steps, func(i, j int) bool {
+		return graph.Steps[i].ID < graph.Steps[j].ID
+	})
+	return graph
+}
+
+func (g *Graph) children() map[int][]StepInfo {
+	children := make(map[int][]StepInfo)
+	for _, info := range g.Steps {
+		children[info.Parent] = append(children[info.Parent], info)
+	}
+	return children
+}
+
+func (g *Graph) WriteDOT(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+	children := g.children()
+
+	fmt.Fprintln(w, "digraph routine {")
+	fmt.Fprintln(w, "\tnode [shape=box, style=\"rounded,filled\"];")
+
+	var writeSteps func(parent int, indent string)
+	writeSteps = func(parent int, indent string) {
+		for _, info := range children[parent] {
+			node := fmt.Sprintf("s%d [label=%q, fillcolor=%q];", info.ID, stepLabel(info, "\n"), statusColor(info.Status))
+			if len(children[info.ID]) == 0 {
+				fmt.Fprintf(w, "%s%s\n", indent, node)
+				continue
+			}
+
+			fmt.Fprintf(w, "%ssubgraph cluster_s%d {\n", indent, info.ID)
+			fmt.Fprintf(w, "%s\tlabel=%q;\n", indent, stepLabel(info, " "))
+			fmt.Fprintf(w, "%s\t%s\n", indent, node)
+			writeSteps(info.ID, indent+"\t")
+			fmt.Fprintf(w, "%s}\n", indent)
+		}
+	}
+	writeSteps(0, "\t")
+
+	for _, edge := range g.edges(children) {
+		fmt.Fprintf(w, "\ts%d -> s%d;\n", edge[0], edge[1])
+	}
+	fmt.Fprintln(w, "}")
+
+	return w.Flush()
+}
+
+func (g *Graph) WriteMermaid(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+	children := g.children()
+
+	fmt.Fprintln(w, "flowchart TD")
+
+	var writeSteps func(parent int, indent string)
+	writeSteps = func(parent int, indent string) {
+		for _, info := range children[parent] {
+			node := fmt.Sprintf("s%d[\"%s\"]", info.ID, stepLabel(info, "<br/>"))
+			if len(children[info.ID]) == 0 {
+				fmt.Fprintf(w, "%s%s\n", indent, node)
+				continue
+			}
+
+			fmt.Fprintf(w, "%ssubgraph scope_s%d [\"%s\"]\n", indent, info.ID, stepLabel(info, " "))
+			fmt.Fprintf(w, "%s\t%s\n", indent, node)
+			writeSteps(info.ID, indent+"\t")
+			fmt.Fprintf(w, "%send\n", indent)
+		}
+	}
+	writeSteps(0, "\t")
+
+	for _, edge := range g.edges(children) {
+		fmt.Fprintf(w, "\ts%d --> s%d\n", edge[0], edge[1])
+	}
+
+	for _, status := range []StepStatus{StatusDone, StatusWaiting, StatusPending} {
+		var ids []string
+		for _, info := range g.Steps {
+			if info.Status == status {
+				ids = append(ids, fmt.Sprintf("s%d", info.ID))
+			}
+		}
+
+		fmt.Fprintf(w, "\tclassDef %s fill:%s\n", status, statusColor(status))
+		if len(ids) > 0 {
+			fmt.Fprintf(w, "\tclass %s %s\n", strings.Join(ids, ","), status)
+		}
+	}
+
+	return w.Flush()
+}
+
+// Edges connect each step with the next step in the same scope and scope with its first step
+func (g *Graph) edges(children map[int][]StepInfo) [][2]int {
+	var edges [][2]int
+	for _, info := range g.Steps {
+		if scope := children[info.ID]; len(scope) > 0 {
+			edges = append(edges, [2]int{info.ID, scope[0].ID})
+		}
+	}
+	for parent := range children {
+		steps := children[parent]
+		for i := 1; i < len(steps); i++ {
+			edges = append(edges, [2]int{steps[i-1].ID, steps[i].ID})
+		}
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+	return edges
+}
+
+func stepLabel(info StepInfo, separator string) string {
+	if info.File == "" {
+		return info.Kind.String()
+	}
+	return fmt.Sprintf("%s%s%s:%d", info.Kind, separator, filepath.Base(info.File), info.Line)
+}
+
+func statusColor(status StepStatus) string {
+	switch status {
+	case StatusDone:
+		return "#b7e4c7"
+	case StatusWaiting:
+		return "#ffe066"
+	default:
+		return "#dee2e6"
+	}
+}
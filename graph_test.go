@@ -0,0 +1,100 @@
+package routines_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRoutine_Graph(t *testing.T) {
+	var events []routines.Event
+	r := routines.NewRoutine(routines.WithTracer(routines.TracerFunc(func(event routines.Event) {
+		events = append(events, event)
+	})))
+
+	r.Start()
+	r.Do(func() {})
+	r.Loop(0, 2, func(i int) {
+		r.WaitFor(time.Hour)
+	})
+	r.End()
+
+	graph := r.Graph()
+	steps := append([]routines.StepInfo(nil), graph.Steps...)
+	for i := range steps {
+		steps[i].Deadline = time.Time{}
+		steps[i].Remaining = 0
+	}
+	test.EqualEl(t, steps, routines.GraphFromTrace(events).Steps)
+
+	type entry struct {
+		kind   routines.StepKind
+		parent int
+		status routines.StepStatus
+	}
+	var actual []entry
+	for _, info := range graph.Steps {
+		test.True(t, strings.HasSuffix(info.File, "graph_test.go"))
+		actual = append(actual, entry{kind: info.Kind, parent: info.Parent, status: info.Status})
+	}
+	test.EqualEl(t, actual, []entry{
+		{kind: routines.StepStart, parent: 0, status: routines.StatusDone},
+		{kind: routines.StepDo, parent: 0, status: routines.StatusDone},
+		{kind: routines.StepLoop, parent: 0, status: routines.StatusWaiting},
+		{kind: routines.StepWaitFor, parent: 3, status: routines.StatusWaiting},
+		{kind: routines.StepWaitFor, parent: 3, status: routines.StatusPending},
+		{kind: routines.StepEnd, parent: 0, status: routines.StatusPending},
+	})
+
+	line := graph.Steps[0].Line
+
+	var dot bytes.Buffer
+	test.Equal(t, graph.WriteDOT(&dot), nil)
+	test.Equal(t, dot.String(), fmt.Sprintf(`digraph routine {
+	node [shape=box, style="rounded,filled"];
+	s1 [label="Start\ngraph_test.go:%[1]d", fillcolor="#b7e4c7"];
+	s2 [label="Do\ngraph_test.go:%[2]d", fillcolor="#b7e4c7"];
+	subgraph cluster_s3 {
+		label="Loop graph_test.go:%[3]d";
+		s3 [label="Loop\ngraph_test.go:%[3]d", fillcolor="#ffe066"];
+		s4 [label="WaitFor\ngraph_test.go:%[4]d", fillcolor="#ffe066"];
+		s5 [label="WaitFor\ngraph_test.go:%[4]d", fillcolor="#dee2e6"];
+	}
+	s6 [label="End\ngraph_test.go:%[5]d", fillcolor="#dee2e6"];
+	s1 -> s2;
+	s2 -> s3;
+	s3 -> s4;
+	s3 -> s6;
+	s4 -> s5;
+}
+`, line, line+1, line+2, line+3, line+5))
+
+	var mermaid bytes.Buffer
+	test.Equal(t, graph.WriteMermaid(&mermaid), nil)
+	test.Equal(t, mermaid.String(), fmt.Sprintf(`flowchart TD
+	s1["Start<br/>graph_test.go:%[1]d"]
+	s2["Do<br/>graph_test.go:%[2]d"]
+	subgraph scope_s3 ["Loop graph_test.go:%[3]d"]
+		s3["Loop<br/>graph_test.go:%[3]d"]
+		s4["WaitFor<br/>graph_test.go:%[4]d"]
+		s5["WaitFor<br/>graph_test.go:%[4]d"]
+	end
+	s6["End<br/>graph_test.go:%[5]d"]
+	s1 --> s2
+	s2 --> s3
+	s3 --> s4
+	s3 --> s6
+	s4 --> s5
+	classDef done fill:#b7e4c7
+	class s1,s2 done
+	classDef waiting fill:#ffe066
+	class s3,s4 waiting
+	classDef pending fill:#dee2e6
+	class s5,s6 pending
+`, line, line+1, line+2, line+3, line+5))
+}
@@ -0,0 +1,47 @@
+package routines
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type LogLevels struct {
+	Step     slog.Level
+	Timeout  slog.Level
+	Restart  slog.Level
+	Complete slog.Level
+	Fail     slog.Level
+}
+
+var DefaultLogLevels = LogLevels{
+	Step:     slog.LevelDebug,
+	Timeout:  slog.LevelWarn,
+	Restart:  slog.LevelInfo,
+	Complete: slog.LevelInfo,
+	Fail:     slog.LevelError,
+}
+
+func (r *Routine) log(level slog.Level, msg string, s *step, extra ...slog.Attr) {
+	if r.logger == nil {
+		return
+	}
+
+	ctx := context.Background()
+	if !r.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, 4+len(extra))
+	if r.name != "" {
+		attrs = append(attrs, slog.String("routine", r.name))
+	}
+	attrs = append(attrs, slog.Int("tick", r.tick))
+	if s != nil {
+		file, line := s.location()
+		attrs = append(attrs, slog.String("step", s.kind.String()), slog.String("at", fmt.Sprintf("%s:%d", file, line)))
+	}
+	attrs = append(attrs, extra...)
+
+	r.logger.LogAttrs(ctx, level, msg, attrs...)
+}
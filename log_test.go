@@ -0,0 +1,65 @@
+package routines_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRoutine_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey || attr.Key == "at" {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+
+	r := routines.NewRoutine(routines.WithName("test"), routines.WithLogger(logger))
+	test.Equal(t, r.Name(), "test")
+
+	r.Start()
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.Do(func() {})
+		r.WaitUntilOrTimeout(func() bool { return false }, waitTime)
+		r.End()
+	}
+	test.True(t, r.Completed())
+	r.Restart()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	test.Equal(t, len(lines), 3)
+	test.True(t, strings.HasPrefix(lines[0], `level=WARN msg="wait timed out" routine=test tick=`))
+	test.True(t, strings.HasSuffix(lines[0], " step=WaitUntilOrTimeout"))
+	test.True(t, strings.HasPrefix(lines[1], `level=INFO msg="routine completed" routine=test tick=`))
+	test.Equal(t, lines[2], `level=INFO msg="routine restarted" routine=test tick=0`)
+
+	buf.Reset()
+	r = routines.StartRoutine(routines.WithLogger(logger), routines.WithLogLevels(routines.LogLevels{
+		Step:     slog.LevelInfo,
+		Timeout:  slog.LevelError,
+		Restart:  slog.LevelDebug,
+		Complete: slog.LevelDebug,
+	}))
+	r.Do(func() {})
+	r.End()
+
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	test.EqualEl(t, lines, []string{
+		`level=INFO msg="step reached" tick=0 step=Start`,
+		`level=INFO msg="step executed" tick=0 step=Start`,
+		`level=INFO msg="step reached" tick=0 step=Do`,
+		`level=INFO msg="step executed" tick=0 step=Do`,
+		`level=INFO msg="step reached" tick=0 step=End`,
+		`level=INFO msg="step executed" tick=0 step=End`,
+	})
+}
@@ -0,0 +1,97 @@
+package routines
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics collects statistics of routines, same metrics can be attached to many routines to aggregate them,
+// it's safe to read metrics concurrently with routine execution
+type Metrics struct {
+	ticks         atomic.Int64
+	stepsExecuted atomic.Int64
+	actionTime    atomic.Int64
+	waitTime      atomic.Int64
+	timeouts      atomic.Int64
+	restarts      atomic.Int64
+	failures      atomic.Int64
+}
+
+type MetricsSnapshot struct {
+	Ticks         int64         `json:"ticks"`
+	StepsExecuted int64         `json:"steps_executed"`
+	ActionTime    time.Duration `json:"action_time_ns"`
+	WaitTime      time.Duration `json:"wait_time_ns"`
+	Timeouts      int64         `json:"timeouts"`
+	Restarts      int64         `json:"restarts"`
+	Failures      int64         `json:"failures"`
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	return MetricsSnapshot{
+		Ticks:         m.ticks.Load(),
+		StepsExecuted: m.stepsExecuted.Load(),
+		ActionTime:    time.Duration(m.actionTime.Load()),
+		WaitTime:      time.Duration(m.waitTime.Load()),
+		Timeouts:      m.timeouts.Load(),
+		Restarts:      m.restarts.Load(),
+		Failures:      m.failures.Load(),
+	}
+}
+
+// String returns metrics as JSON, so metrics can be used as expvar.Var
+func (m *Metrics) String() string {
+	data, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, m)
+}
+
+func (r *Routine) measure(update func(m *Metrics)) {
+	for _, m := range r.metrics {
+		update(m)
+	}
+}
+
+func (r *Routine) measureAction(action func()) func() {
+	if len(r.metrics) == 0 {
+		return action
+	}
+
+	return func() {
+		start := time.Now()
+		defer func() {
+			elapsed := int64(time.Since(start))
+			r.measure(func(m *Metrics) {
+				m.actionTime.Add(elapsed)
+			})
+		}()
+		action()
+	}
+}
+
+func (r *Routine) measureStep(s *step) {
+	if len(r.metrics) == 0 {
+		return
+	}
+
+	var waited int64
+	if s.kind.IsWait() && !s.reachedAt.IsZero() {
+		waited = int64(r.now().Sub(s.reachedAt))
+	}
+	r.measure(func(m *Metrics) {
+		m.stepsExecuted.Add(1)
+		m.waitTime.Add(waited)
+	})
+}
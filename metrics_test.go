@@ -0,0 +1,79 @@
+package routines_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestMetrics(t *testing.T) {
+	// Published names are global, so each run of the test uses its own name
+	name := "routines_test_total_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	total := routines.NewMetrics()
+	total.Publish(name)
+
+	m1 := routines.NewMetrics()
+	r1 := routines.StartRoutine(routines.WithMetrics(m1), routines.WithMetrics(total))
+	r2 := routines.StartRoutine(routines.WithMetrics(total))
+
+	start := time.Now()
+	for !(r1.Completed() && r2.Completed()) && time.Since(start) < maxDuration {
+		r1.Do(func() {
+			time.Sleep(waitTime)
+		})
+		r1.WaitFor(waitTime)
+		r1.End()
+
+		r2.WaitUntilOrTimeout(func() bool { return false }, waitTime)
+		r2.End()
+	}
+	test.True(t, r1.Completed() && r2.Completed())
+	r2.Restart()
+
+	s1 := m1.Snapshot()
+	test.True(t, s1.Ticks > 1)
+	test.Equal(t, s1.StepsExecuted, 4)
+	test.True(t, s1.ActionTime >= waitTime)
+	test.True(t, s1.WaitTime >= waitTime)
+	test.Equal(t, s1.Timeouts, 0)
+	test.Equal(t, s1.Restarts, 0)
+
+	s := total.Snapshot()
+	test.True(t, s.Ticks > s1.Ticks)
+	test.Equal(t, s.StepsExecuted, 4+3+1)
+	test.Equal(t, s.Timeouts, 1)
+	test.Equal(t, s.Restarts, 1)
+	test.True(t, s.WaitTime >= 2*waitTime)
+
+	var published routines.MetricsSnapshot
+	test.Equal(t, json.Unmarshal([]byte(expvar.Get(name).String()), &published), nil)
+	test.Equal(t, published, s)
+}
+
+func TestMetrics_Paused(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	metrics := routines.NewMetrics()
+	r := routines.StartRoutine(routines.WithClock(clock), routines.WithMetrics(metrics))
+
+	for i := 0; !r.Completed() && i < maxLoop; i++ {
+		r.WaitFor(time.Second)
+		r.End()
+
+		switch i {
+		case 0:
+			r.Pause()
+			clock.now = clock.now.Add(time.Hour)
+		case 1:
+			r.Resume()
+			clock.now = clock.now.Add(time.Second)
+		}
+	}
+
+	test.True(t, r.Completed())
+	test.Equal(t, metrics.Snapshot().WaitTime, time.Second)
+}
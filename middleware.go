@@ -0,0 +1,63 @@
+package routines
+
+import "slices"
+
+type Middleware func(step StepInfo, next func())
+
+// Use adds middleware that wraps execution of every step, middlewares added first are called first
+func (r *Routine) Use(middleware Middleware) {
+	r.middlewares = append(r.middlewares, middleware)
+}
+
+// OnStart adds hook called when routine starts, hooks return func that removes them
+func (r *Routine) OnStart(hook func()) func() {
+	return addHook(&r.onStart, hook)
+}
+
+func (r *Routine) OnEnd(hook func()) func() {
+	return addHook(&r.onEnd, hook)
+}
+
+func (r *Routine) OnReset(hook func()) func() {
+	return addHook(&r.onReset, hook)
+}
+
+// OnTick adds hook called at the end of each tick of started routine, after End, it's also called by End of completed
+// or failed routine
+func (r *Routine) OnTick(hook func()) func() {
+	return addHook(&r.onTick, hook)
+}
+
+func (r *Routine) run(caller string, s *step, body func()) {
+	if len(r.middlewares) == 0 {
+		body()
+		return
+	}
+
+	info := r.stepInfo(caller, s)
+	next := body
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		middleware, inner := r.middlewares[i], next
+		next = func() {
+			middleware(info, inner)
+		}
+	}
+	next()
+}
+
+// Hooks are removed from a copy, so hook can remove itself while hooks are running
+func addHook(hooks *[]*func(), hook func()) func() {
+	added := &hook
+	*hooks = append(*hooks, added)
+	return func() {
+		*hooks = slices.DeleteFunc(slices.Clone(*hooks), func(h *func()) bool {
+			return h == added
+		})
+	}
+}
+
+func runHooks(hooks []*func()) {
+	for _, hook := range hooks {
+		(*hook)()
+	}
+}
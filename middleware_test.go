@@ -0,0 +1,138 @@
+package routines_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRoutine_Use(t *testing.T) {
+	r := routines.NewRoutine()
+
+	var calls []string
+	r.Use(func(step routines.StepInfo, next func()) {
+		calls = append(calls, "outer:"+step.Kind.String())
+		next()
+	})
+	r.Use(func(step routines.StepInfo, next func()) {
+		calls = append(calls, "inner:"+step.Kind.String())
+		if step.Kind == routines.StepDo && step.Parent != 0 {
+			return
+		}
+		next()
+	})
+
+	e1 := 0
+	e2 := 0
+
+	r.Start()
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.Do(func() {
+			e1++
+		})
+		r.Func(func() {
+			r.Do(func() {
+				e2++
+			})
+		})
+		r.WaitUntil(func() bool {
+			return true
+		})
+		r.End()
+	}
+
+	test.True(t, r.Completed())
+	test.Equal(t, e1, 1)
+	test.Equal(t, e2, 0)
+	test.EqualEl(t, calls, []string{
+		"outer:Do", "inner:Do",
+		"outer:Func", "inner:Func", "outer:Do", "inner:Do",
+		"outer:WaitUntil", "inner:WaitUntil",
+	})
+}
+
+func TestRoutine_Use_Recover(t *testing.T) {
+	r := routines.StartRoutine()
+
+	var recovered any
+	r.Use(func(step routines.StepInfo, next func()) {
+		defer func() {
+			if v := recover(); v != nil {
+				recovered = v
+			}
+		}()
+		next()
+	})
+
+	e1 := false
+	r.Do(func() {
+		panic("failed")
+	})
+	r.Do(func() {
+		e1 = true
+	})
+	r.End()
+
+	test.Equal(t, recovered, any("failed"))
+	test.True(t, e1)
+	test.True(t, r.Completed())
+}
+
+func TestRoutine_Hooks(t *testing.T) {
+	r := routines.NewRoutine()
+
+	var calls []string
+	r.OnStart(func() {
+		calls = append(calls, "start")
+	})
+	r.OnEnd(func() {
+		calls = append(calls, "end")
+	})
+	r.OnReset(func() {
+		calls = append(calls, "reset")
+	})
+
+	r.Start()
+	r.Start()
+	r.End()
+	r.End()
+	r.Restart()
+
+	test.EqualEl(t, calls, []string{"start", "end", "reset", "start"})
+}
+
+func TestRoutine_OnTick(t *testing.T) {
+	metrics := routines.NewMetrics()
+	r := routines.NewRoutine(routines.WithMetrics(metrics))
+
+	ticks, allTicks := 0, 0
+	remove := r.OnTick(func() {
+		ticks++
+	})
+	r.OnTick(func() {
+		allTicks++
+	})
+
+	for i := 0; i < 4; i++ {
+		switch i {
+		case 1:
+			r.Start()
+		case 2:
+			remove()
+			remove()
+		case 3:
+			r.Fail(errors.New("failed"))
+		}
+
+		r.WaitFor(time.Hour)
+		r.End()
+	}
+
+	test.Equal(t, ticks, 1)
+	test.Equal(t, allTicks, 3)
+	test.Equal(t, metrics.Snapshot().Ticks, 2)
+}
@@ -0,0 +1,78 @@
+package routines
+
+import "log/slog"
+
+type Option func(r *Routine)
+
+func WithTracer(tracer Tracer) Option {
+	return func(r *Routine) {
+		r.tracer = tracer
+	}
+}
+
+func WithName(name string) Option {
+	return func(r *Routine) {
+		r.name = name
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Routine) {
+		r.logger = logger
+	}
+}
+
+func WithLogLevels(levels LogLevels) Option {
+	return func(r *Routine) {
+		r.logLevels = levels
+	}
+}
+
+// WithSteps records steps of routine for Steps, Graph and Progress from the start, steps are also recorded when tracer,
+// logger, metrics or middleware is used, or after the first call of Steps, Graph or Progress
+func WithSteps() Option {
+	return func(r *Routine) {
+		r.recordSteps = true
+	}
+}
+
+func WithMetrics(metrics *Metrics) Option {
+	return func(r *Routine) {
+		r.metrics = append(r.metrics, metrics)
+	}
+}
+
+// WithSkippableConditions configures whether SkipWait completes conditional waiters like WaitUntil or WaitForDone,
+// by default they are skippable
+func WithSkippableConditions(skippable bool) Option {
+	return func(r *Routine) {
+		r.skipConditions = skippable
+	}
+}
+
+// WithClock replaces wall clock used by routine, it's useful for testing
+func WithClock(clock Clock) Option {
+	return func(r *Routine) {
+		r.clock = clock
+		r.clockWall = clock.Now()
+		r.clockTime = r.clockWall
+	}
+}
+
+// WithSeed makes random steps of routine reproducible, same seed produces same choices after each reset
+func WithSeed(seed uint64) Option {
+	return func(r *Routine) {
+		r.seed = seed
+		r.seeded = true
+		r.source.Seed(seed, seed)
+	}
+}
+
+// WithTimeScale sets initial time scale of routine, it panics if scale is invalid
+func WithTimeScale(scale float64) Option {
+	return func(r *Routine) {
+		if err := r.SetTimeScale(scale); err != nil {
+			panic(err)
+		}
+	}
+}
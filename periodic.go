@@ -0,0 +1,83 @@
+package routines
+
+import "time"
+
+// Every runs action on a fixed rate schedule without blocking next steps, runs that were missed are skipped,
+// action runs until enclosing block completes
+func (r *Routine) Every(interval time.Duration, action func()) {
+	r.periodic(r.caller(), StepEvery, interval, nil, action)
+}
+
+// EveryUntil runs action on a fixed rate schedule until stop condition is true
+func (r *Routine) EveryUntil(interval time.Duration, stop func() bool, action func()) {
+	r.periodic(r.caller(), StepEvery, interval, stop, action)
+}
+
+// Interval runs action with a fixed delay between the end of one run and the start of the next one without blocking
+// next steps, action runs until enclosing block completes
+func (r *Routine) Interval(delay time.Duration, action func()) {
+	r.periodic(r.caller(), StepInterval, delay, nil, action)
+}
+
+// IntervalUntil runs action with a fixed delay between runs until stop condition is true
+func (r *Routine) IntervalUntil(delay time.Duration, stop func() bool, action func()) {
+	r.periodic(r.caller(), StepInterval, delay, stop, action)
+}
+
+// Periodic step is executed as soon as it's reached, so next steps can continue, but it keeps running its action
+// each time it's called until stopped
+func (r *Routine) periodic(pc uintptr, kind StepKind, interval time.Duration, stop func() bool, action func()) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(pc)
+	defer pop()
+
+	s := r.step(caller, kind)
+	if !r.isExecuted(caller) {
+		if !r.isPrevExecuted(caller) {
+			r.skip(s)
+			return
+		}
+		r.reach(s)
+		r.addExecution(caller)
+		r.markAsExecuted(caller)
+
+		now := r.now()
+		r.timers[caller] = timer{
+			start:    now,
+			deadline: now.Add(interval),
+		}
+	}
+
+	if _, stopped := r.values[caller]; stopped {
+		return
+	}
+	if stop != nil && stop() {
+		r.values[caller] = struct{}{}
+		delete(r.timers, caller)
+		r.finish(s)
+		return
+	}
+
+	t := r.timers[caller]
+	if !r.isPassed(t.deadline) {
+		return
+	}
+
+	r.run(caller, s, r.measureAction(action))
+
+	now := r.now()
+	next := now.Add(interval)
+	if kind == StepEvery && interval > 0 {
+		next = t.deadline.Add(interval)
+		for !next.After(now) {
+			next = next.Add(interval)
+		}
+	}
+	r.timers[caller] = timer{
+		start:    now,
+		deadline: next,
+	}
+}
@@ -0,0 +1,74 @@
+package routines_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRoutine_Every(t *testing.T) {
+	const interval = waitTime * 20
+
+	r := routines.StartRoutine(routines.WithSteps())
+
+	e1, e2, runs := 0, 0, 0
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.Func(func() {
+			r.Every(interval, func() {
+				e1++
+			})
+			r.WaitFor(interval*5 + interval/2)
+		})
+		r.Do(func() {
+			runs = e1
+		})
+		r.WaitFor(interval * 2)
+		r.Do(func() {
+			e2++
+		})
+		r.End()
+	}
+
+	test.True(t, r.Completed())
+	test.Equal(t, runs, 5)
+	test.Equal(t, e1, 5)
+	test.Equal(t, e2, 1)
+	test.Equal(t, r.Steps()[2].Kind, routines.StepEvery)
+}
+
+func TestRoutine_IntervalUntil(t *testing.T) {
+	r := routines.StartRoutine(routines.WithSteps())
+
+	e1, e2 := 0, 0
+	var last time.Time
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.IntervalUntil(waitTime*5, func() bool {
+			return e1 == 3
+		}, func() {
+			if !last.IsZero() {
+				test.True(t, time.Since(last) >= waitTime*5)
+			}
+			e1++
+			time.Sleep(waitTime)
+			last = time.Now()
+		})
+		r.Do(func() {
+			e2++
+		})
+		r.WaitUntil(func() bool {
+			return e1 == 3
+		})
+		r.WaitFor(waitTime * 20)
+		r.End()
+	}
+
+	test.True(t, r.Completed())
+	test.Equal(t, e1, 3)
+	test.Equal(t, e2, 1)
+	test.Equal(t, r.Steps()[1].Kind, routines.StepInterval)
+	test.Equal(t, r.Steps()[1].Status, routines.StatusDone)
+}
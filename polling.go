@@ -0,0 +1,83 @@
+package routines
+
+import "time"
+
+// WaitUntilEvery waits for condition to be true, condition is checked at most once per interval
+func (r *Routine) WaitUntilEvery(condition func() bool, interval time.Duration) {
+	r.waitUntilPolling(r.caller(), StepWaitUntilEvery, condition, ConstantBackoff(interval), 0)
+}
+
+func (r *Routine) WaitUntilEveryOrTimeout(condition func() bool, interval, duration time.Duration) {
+	r.waitUntilPolling(r.caller(), StepWaitUntilEveryOrTimeout, condition, ConstantBackoff(interval), duration)
+}
+
+// WaitUntilBackoff waits for condition to be true, delays between checks of condition are taken from backoff
+func (r *Routine) WaitUntilBackoff(condition func() bool, backoff Backoff) {
+	r.waitUntilPolling(r.caller(), StepWaitUntilBackoff, condition, backoff, 0)
+}
+
+func (r *Routine) WaitUntilBackoffOrTimeout(condition func() bool, backoff Backoff, duration time.Duration) {
+	r.waitUntilPolling(r.caller(), StepWaitUntilBackoffOrTimeout, condition, backoff, duration)
+}
+
+type pollState struct {
+	checks int
+	next   time.Time
+}
+
+// Condition is checked when wait is reached and then after each delay, timeout is used only if it's positive
+func (r *Routine) waitUntilPolling(
+	pc uintptr, kind StepKind, condition func() bool, backoff Backoff, timeout time.Duration,
+) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(pc)
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, kind)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	r.addExecution(caller)
+	r.run(caller, s, func() {
+		if timeout > 0 {
+			deadline := r.executionTimer(caller, timeout)
+			if r.isExpired(deadline) {
+				r.markAsExecuted(caller)
+				r.expire(s, deadline)
+				return
+			}
+		}
+
+		if r.consumeSkipCondition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+			return
+		}
+
+		state := executionValues(r, caller, func() *pollState {
+			return &pollState{
+				next: r.now(),
+			}
+		})
+		if !r.isExpired(state.next) {
+			return
+		}
+
+		state.checks++
+		if condition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+			return
+		}
+		state.next = r.now().Add(max(backoff(state.checks), 0))
+	})
+}
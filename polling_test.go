@@ -0,0 +1,64 @@
+package routines_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRoutine_WaitUntilEvery(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	r := routines.StartRoutine(routines.WithClock(clock))
+
+	checks, e1 := 0, 0
+	done := false
+	for i := 0; i < 20; i++ {
+		r.WaitUntilEvery(func() bool {
+			checks++
+			return done
+		}, time.Second)
+		r.Do(func() {
+			e1++
+		})
+		r.End()
+
+		switch i {
+		case 5:
+			test.Equal(t, checks, 1)
+			clock.now = clock.now.Add(time.Second)
+		case 10:
+			test.Equal(t, checks, 2)
+			done = true
+		case 15:
+			test.Equal(t, checks, 2)
+			clock.now = clock.now.Add(time.Second)
+		}
+	}
+
+	test.Equal(t, checks, 3)
+	test.Equal(t, e1, 1)
+	test.True(t, r.Completed())
+}
+
+func TestRoutine_WaitUntilBackoffOrTimeout(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	r := routines.StartRoutine(routines.WithClock(clock))
+
+	var checkedAt []time.Duration
+	start := clock.now
+	for i := 0; !r.Completed() && i < maxLoop; i++ {
+		r.WaitUntilBackoffOrTimeout(func() bool {
+			checkedAt = append(checkedAt, clock.now.Sub(start))
+			return false
+		}, routines.ExponentialBackoff(time.Second, 0), time.Second*10)
+		r.End()
+
+		clock.now = clock.now.Add(time.Second)
+	}
+
+	test.True(t, r.Completed())
+	test.EqualEl(t, checkedAt, []time.Duration{0, time.Second, time.Second * 3, time.Second * 7})
+	test.Equal(t, clock.now.Sub(start), time.Second*11)
+}
@@ -0,0 +1,44 @@
+package routines
+
+// Progress estimates completion of the routine in range [0, 1] based on steps known after the first pass, timed waits
+// count as partially done by their elapsed time, returned value never decreases and is 1 only when routine is completed,
+// steps are recorded after the first call unless routine records them from the start
+func (r *Routine) Progress() float64 {
+	r.recordSteps = true
+	if r.completed {
+		return 1
+	}
+
+	scopes := make(map[int]bool)
+	for _, s := range r.steps {
+		scopes[s.parent] = true
+	}
+
+	total := 0
+	done := 0.0
+	for caller, s := range r.steps {
+		if scopes[s.id] {
+			continue
+		}
+		total++
+
+		if r.isExecuted(caller) {
+			done++
+			continue
+		}
+		if t, found := r.timers[caller]; found {
+			duration := t.deadline.Sub(t.start)
+			if duration > 0 {
+				done += min(float64(r.now().Sub(t.start))/float64(duration), 1)
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	// Not completed routine has at least End left, so it never reports full progress
+	progress := min(done/float64(total), float64(total-1)/float64(total))
+	r.progress = max(r.progress, progress)
+	return r.progress
+}
@@ -0,0 +1,112 @@
+package routines
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// WaitForRandom waits for random time in range [minDuration, maxDuration], duration is chosen once when wait is
+// reached
+func (r *Routine) WaitForRandom(minDuration, maxDuration time.Duration) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepWaitForRandom)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	r.run(caller, s, func() {
+		duration := minDuration
+		if _, found := r.timers[caller]; !found && maxDuration > minDuration {
+			duration += time.Duration(r.rand.Int64N(int64(maxDuration-minDuration) + 1))
+		}
+
+		deadline := r.executionTimer(caller, duration)
+		if r.isExpired(deadline) || r.consumeSkipWait() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+		}
+	})
+}
+
+// Choose picks one of branches randomly with given weights and calls it with other actions inside, branch is chosen
+// once when step is reached, it panics if number of weights doesn't match number of branches
+func (r *Routine) Choose(weights []float64, branches ...func()) {
+	if len(weights) != len(branches) {
+		panic(fmt.Errorf("got %d weights for %d branches", len(weights), len(branches)))
+	}
+
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepChoose)
+	if !r.isPrevExecutedTo(r.executionSequenceIndex(caller)) {
+		r.skip(s)
+		return
+	}
+	r.reachScope(caller, s)
+
+	// Branch is chosen only when steps before it are executed, so random source isn't drawn ahead of time
+	if _, found := r.values[caller]; !found && !r.isPrevExecuted(caller) {
+		return
+	}
+	branch := executionValues(r, caller, func() int {
+		return r.choose(weights)
+	})
+	r.run(caller, s, func() {
+		_, popBranch := r.pushToStack(uintptr(branch))
+		branches[branch]()
+		popBranch()
+	})
+
+	if r.running() && r.isPrevExecuted(caller) {
+		r.addExecution(caller)
+		r.markAsExecuted(caller)
+		r.finish(s)
+	}
+}
+
+func (r *Routine) choose(weights []float64) int {
+	total := 0.0
+	for _, weight := range weights {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			panic(fmt.Errorf("invalid weight: %v", weight))
+		}
+		total += weight
+	}
+	if total == 0 {
+		panic(fmt.Errorf("no positive weights"))
+	}
+
+	value := r.rand.Float64() * total
+	last := 0
+	for i, weight := range weights {
+		if weight == 0 {
+			continue
+		}
+		if value < weight {
+			return i
+		}
+		value -= weight
+		last = i
+	}
+	return last
+}
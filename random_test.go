@@ -0,0 +1,142 @@
+package routines_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRoutine_Random(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+
+	run := func(r *routines.Routine) (time.Duration, []int) {
+		var remaining time.Duration
+		var choices []int
+		for i := 0; !r.Completed() && i < maxLoop; i++ {
+			r.WaitForRandom(time.Second, time.Second*2)
+			r.Repeat(10, func() {
+				r.Choose([]float64{1, 2, 0, 3}, func() {
+					r.Do(func() {
+						choices = append(choices, 0)
+					})
+				}, func() {
+					r.Do(func() {
+						choices = append(choices, 1)
+					})
+				}, func() {
+					r.Do(func() {
+						choices = append(choices, 2)
+					})
+				}, func() {
+					r.WaitForRandom(time.Second, time.Second)
+					r.Do(func() {
+						choices = append(choices, 3)
+					})
+				})
+			})
+			r.End()
+
+			if i == 0 {
+				remaining = r.Steps()[1].Remaining
+				r.SkipAll()
+			}
+		}
+
+		test.True(t, r.Completed())
+		return remaining, choices
+	}
+
+	r1 := routines.StartRoutine(routines.WithClock(clock), routines.WithSeed(42), routines.WithSteps())
+	remaining1, choices1 := run(r1)
+	test.True(t, remaining1 >= time.Second && remaining1 <= time.Second*2)
+	test.Equal(t, len(choices1), 10)
+	for _, choice := range choices1 {
+		test.True(t, choice != 2)
+	}
+
+	r2 := routines.StartRoutine(routines.WithClock(clock), routines.WithSeed(42), routines.WithSteps())
+	remaining2, choices2 := run(r2)
+	test.Equal(t, remaining2, remaining1)
+	test.EqualEl(t, choices2, choices1)
+
+	r1.Restart()
+	remaining3, choices3 := run(r1)
+	test.Equal(t, remaining3, remaining1)
+	test.EqualEl(t, choices3, choices1)
+
+	r3 := routines.StartRoutine(routines.WithClock(clock), routines.WithSeed(7), routines.WithSteps())
+	_, choices4 := run(r3)
+	test.Equal(t, len(choices4), 10)
+
+	defer func() {
+		test.True(t, recover() != nil)
+	}()
+	r3.Restart()
+	r3.Choose([]float64{1}, func() {}, func() {})
+}
+
+func TestRoutine_Choose_AfterWait(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	r := routines.StartRoutine(routines.WithClock(clock), routines.WithSteps())
+
+	calls := 0
+	for i := 0; i < 4; i++ {
+		r.WaitFor(time.Second)
+		r.Choose([]float64{1, 1}, func() {
+			calls++
+		}, func() {
+			calls++
+		})
+		r.End()
+
+		if i < 2 {
+			test.Equal(t, calls, 0)
+			test.Equal(t, r.Steps()[2].Status, routines.StatusPending)
+		}
+		if i == 1 {
+			clock.now = clock.now.Add(time.Second)
+		}
+	}
+
+	test.Equal(t, calls, 1)
+	test.True(t, r.Completed())
+}
+
+func TestRoutine_Choose_Wait(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	r := routines.StartRoutine(routines.WithClock(clock))
+
+	var order []string
+	for i := 0; i < 4; i++ {
+		r.Do(func() {
+			order = append(order, "a")
+		})
+		r.Choose([]float64{1, 1}, func() {
+			r.WaitFor(time.Second)
+			r.Do(func() {
+				order = append(order, "b")
+			})
+		}, func() {
+			r.WaitFor(time.Second)
+			r.Do(func() {
+				order = append(order, "b")
+			})
+		})
+		r.Do(func() {
+			order = append(order, "c")
+		})
+		r.End()
+
+		if i < 2 {
+			test.EqualEl(t, order, []string{"a"})
+		}
+		if i == 1 {
+			clock.now = clock.now.Add(time.Second)
+		}
+	}
+
+	test.EqualEl(t, order, []string{"a", "b", "c"})
+	test.True(t, r.Completed())
+}
@@ -0,0 +1,119 @@
+package routines
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff returns delay before the next attempt, attempt starts from 1
+type Backoff func(attempt int) time.Duration
+
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff doubles delay after each attempt, delay never exceeds maxDelay if it's positive
+func ExponentialBackoff(initial, maxDelay time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		delay := initial
+		for i := 1; i < attempt && delay < math.MaxInt64/2; i++ {
+			if maxDelay > 0 && delay >= maxDelay {
+				break
+			}
+			delay *= 2
+		}
+		if maxDelay > 0 {
+			return min(delay, maxDelay)
+		}
+		return delay
+	}
+}
+
+// RetryPolicy limits retries by number of attempts and by duration since the first attempt, zero means no limit,
+// jitter randomly changes delay of backoff by up to its fraction in both directions using random source of routine
+type RetryPolicy struct {
+	Backoff     Backoff
+	Jitter      float64
+	MaxAttempts int
+	MaxDuration time.Duration
+}
+
+func (p RetryPolicy) delay(attempt int, random *rand.Rand) time.Duration {
+	if p.Backoff == nil {
+		return 0
+	}
+	delay := p.Backoff(attempt)
+	if p.Jitter > 0 {
+		delay = time.Duration(float64(delay) * (1 + p.Jitter*(2*random.Float64()-1)))
+	}
+	return max(delay, 0)
+}
+
+type retryState struct {
+	attempt int
+	start   time.Time
+}
+
+// Retry runs action until it succeeds, waits between attempts don't block, when policy is exhausted routine fails
+// with the last error
+func (r *Routine) Retry(policy RetryPolicy, action func() error) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepRetry)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	r.addExecution(caller)
+	r.run(caller, s, func() {
+		state := executionValues(r, caller, func() *retryState {
+			return &retryState{
+				start: r.now(),
+			}
+		})
+
+		if t, found := r.timers[caller]; found {
+			if !r.isExpired(t.deadline) && !r.consumeSkipWait() {
+				return
+			}
+			delete(r.timers, caller)
+		}
+
+		var err error
+		state.attempt++
+		r.measureAction(func() {
+			err = action()
+		})()
+		if err == nil {
+			r.markAsExecuted(caller)
+			r.finish(s)
+			return
+		}
+
+		now := r.now()
+		delay := policy.delay(state.attempt, r.rand)
+		if policy.MaxAttempts > 0 && state.attempt >= policy.MaxAttempts ||
+			policy.MaxDuration > 0 && now.Add(delay).Sub(state.start) > policy.MaxDuration {
+			r.fail(s, err)
+			return
+		}
+
+		r.timers[caller] = timer{
+			start:    now,
+			deadline: now.Add(delay),
+		}
+	})
+}
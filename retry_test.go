@@ -0,0 +1,143 @@
+package routines_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRoutine_Retry(t *testing.T) {
+	r := routines.StartRoutine()
+
+	attempts, e1 := 0, 0
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.Retry(routines.RetryPolicy{
+			Backoff:     routines.ConstantBackoff(waitTime * 10),
+			MaxAttempts: 5,
+		}, func() error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("transient")
+			}
+			return nil
+		})
+		r.Do(func() {
+			e1++
+		})
+		r.End()
+	}
+
+	test.True(t, r.Completed())
+	test.True(t, r.Done())
+	test.False(t, r.Failed())
+	test.Equal(t, attempts, 3)
+	test.Equal(t, e1, 1)
+	test.True(t, time.Since(start) >= waitTime*20)
+}
+
+func TestRoutine_Retry_Fail(t *testing.T) {
+	metrics := routines.NewMetrics()
+	r := routines.StartRoutine(routines.WithMetrics(metrics))
+
+	errFailed := errors.New("failed")
+	attempts, e1 := 0, 0
+	start := time.Now()
+	for !r.Failed() && time.Since(start) < maxDuration {
+		r.Retry(routines.RetryPolicy{
+			Backoff:     routines.ExponentialBackoff(waitTime, waitTime*4),
+			MaxDuration: waitTime * 20,
+		}, func() error {
+			attempts++
+			return fmt.Errorf("attempt %d: %w", attempts, errFailed)
+		})
+		r.Do(func() {
+			e1++
+		})
+		r.End()
+	}
+
+	test.True(t, r.Failed())
+	test.False(t, r.Completed())
+	test.False(t, r.Started())
+	test.True(t, errors.Is(r.Err(), errFailed))
+	test.True(t, attempts > 2 && attempts < 10)
+	test.Equal(t, e1, 0)
+	test.Equal(t, r.Steps()[1].Status, routines.StatusWaiting)
+	test.Equal(t, metrics.Snapshot().Failures, int64(1))
+
+	r.Restart()
+	test.False(t, r.Failed())
+	test.Equal(t, r.Err(), nil)
+}
+
+func TestRoutine_Fail(t *testing.T) {
+	r := routines.StartRoutine()
+
+	errFailed := errors.New("failed")
+	e1 := 0
+	for i := 0; i < 3; i++ {
+		r.Do(func() {
+			r.Fail(errFailed)
+		})
+		r.Do(func() {
+			e1++
+		})
+		r.End()
+	}
+
+	test.Equal(t, r.Err(), errFailed)
+	test.Equal(t, e1, 0)
+	test.False(t, r.Completed())
+	test.True(t, r.Done())
+
+	g := routines.NewGenerator(func(g *routines.Generator[int]) {
+		g.Do(func() {
+			g.Fail(errFailed)
+		})
+		g.Yield(1)
+	})
+	_, ok := g.Next()
+	test.False(t, ok)
+	test.True(t, g.Done())
+}
+
+func TestBackoff(t *testing.T) {
+	exponential := routines.ExponentialBackoff(time.Second, time.Second*5)
+	test.Equal(t, exponential(1), time.Second)
+	test.Equal(t, exponential(2), time.Second*2)
+	test.Equal(t, exponential(3), time.Second*4)
+	test.Equal(t, exponential(4), time.Second*5)
+	test.Equal(t, exponential(100), time.Second*5)
+
+	unlimited := routines.ExponentialBackoff(time.Second, 0)
+	test.Equal(t, unlimited(4), time.Second*8)
+	test.True(t, unlimited(1000) > 0)
+}
+
+func TestRoutine_Retry_Jitter(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+
+	run := func(r *routines.Routine) time.Duration {
+		r.Retry(routines.RetryPolicy{
+			Backoff: routines.ConstantBackoff(time.Second),
+			Jitter:  0.5,
+		}, func() error {
+			return errors.New("failed")
+		})
+		r.End()
+		return r.Steps()[1].Remaining
+	}
+
+	r1 := routines.StartRoutine(routines.WithClock(clock), routines.WithSeed(42), routines.WithSteps())
+	delay := run(r1)
+	test.True(t, delay >= time.Second/2 && delay <= time.Second*3/2)
+	test.True(t, delay != time.Second)
+
+	r2 := routines.StartRoutine(routines.WithClock(clock), routines.WithSeed(42), routines.WithSteps())
+	test.Equal(t, run(r2), delay)
+}
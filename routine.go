@@ -1,42 +1,118 @@
 package routines
 
-import "time"
+import (
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
 
 type Routine struct {
+	name              string
 	started           bool
 	completed         bool
+	err               error
+	suspended         bool
+	paused            bool
+	skipWait          bool
+	fastMode          bool
+	skipConditions    bool
+	clock             Clock
+	clockWall         time.Time
+	clockTime         time.Time
+	timeScale         float64
+	tick              int
+	lastDeadline      time.Time
+	seed              uint64
+	seeded            bool
+	source            *rand.PCG
+	rand              *rand.Rand
 	executionStack    []uintptr
 	executionSeqIndex map[string]int
 	executionSequence []string
 	executed          map[string]struct{}
-	timers            map[string]<-chan time.Time
+	timers            map[string]timer
+	values            map[string]any
+	steps             map[string]*step
+	recordSteps       bool
+	iterations        map[string]struct{}
+	tracer            Tracer
+	logger            *slog.Logger
+	logLevels         LogLevels
+	metrics           []*Metrics
+	middlewares       []Middleware
+	onStart           []*func()
+	onEnd             []*func()
+	onReset           []*func()
+	onTick            []*func()
+	progress          float64
 	pc                [1]uintptr
 }
 
-func NewRoutine() *Routine {
+func NewRoutine(options ...Option) *Routine {
+	source := rand.NewPCG(rand.Uint64(), rand.Uint64())
 	routine := &Routine{
-		pc: [1]uintptr{},
+		source:         source,
+		rand:           rand.New(source),
+		clock:          systemClock{},
+		timeScale:      1,
+		skipConditions: true,
+		logLevels:      DefaultLogLevels,
+		pc:             [1]uintptr{},
+	}
+	routine.reset()
+	for _, option := range options {
+		option(routine)
 	}
-	routine.Reset()
 	return routine
 }
 
 func (r *Routine) Reset() {
+	r.reset()
+	r.trace(EventReset, nil, 0)
+	runHooks(r.onReset)
+}
+
+func (r *Routine) reset() {
 	r.started = false
 	r.completed = false
+	r.err = nil
+	r.suspended = false
+	r.paused = false
+	r.skipWait = false
+	r.fastMode = false
+	r.clockWall = r.clock.Now()
+	r.clockTime = r.clockWall
+	r.tick = 0
+	r.lastDeadline = time.Time{}
+	if r.seeded {
+		r.source.Seed(r.seed, r.seed)
+	}
+	r.progress = 0
 	r.executionStack = make([]uintptr, 0)
 	r.executionSeqIndex = make(map[string]int)
 	r.executionSequence = make([]string, 0)
 	r.executed = make(map[string]struct{})
-	r.timers = make(map[string]<-chan time.Time)
+	r.timers = make(map[string]timer)
+	for _, value := range r.values {
+		if p, ok := value.(interface{ release() }); ok {
+			p.release()
+		}
+	}
+	r.values = make(map[string]any)
+	r.steps = make(map[string]*step)
+	r.iterations = make(map[string]struct{})
 }
 
-func StartRoutine() *Routine {
-	routine := NewRoutine()
+func StartRoutine(options ...Option) *Routine {
+	routine := NewRoutine(options...)
 	routine.Start()
 	return routine
 }
 
+func (r *Routine) Name() string {
+	return r.name
+}
+
 func (r *Routine) Started() bool {
 	return r.started
 }
@@ -44,3 +120,30 @@ func (r *Routine) Started() bool {
 func (r *Routine) Completed() bool {
 	return r.completed
 }
+
+// Done reports whether routine is completed or failed, in both cases it executes no more steps until it's reset
+func (r *Routine) Done() bool {
+	return r.completed || r.err != nil
+}
+
+// Fail stops routine with an error, routine stays failed until it's reset
+func (r *Routine) Fail(err error) {
+	if err == nil || r.err != nil {
+		return
+	}
+	r.fail(nil, err)
+}
+
+func (r *Routine) Failed() bool {
+	return r.err != nil
+}
+
+func (r *Routine) Err() error {
+	return r.err
+}
+
+// Steps returns known steps of routine, steps are recorded after the first call unless routine records them from the start
+func (r *Routine) Steps() []StepInfo {
+	r.recordSteps = true
+	return r.stepInfos()
+}
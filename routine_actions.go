@@ -1,5 +1,7 @@
 package routines
 
+import "time"
+
 func (r *Routine) Start() {
 	if r.started {
 		return
@@ -11,22 +13,40 @@ func (r *Routine) Start() {
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepStart)
 	if !r.isPrevExecuted(caller) {
+		r.skip(s)
 		return
 	}
+	r.reach(s)
 	r.addExecution(caller)
 	r.markAsExecuted(caller)
 
 	r.started = true
+	r.finish(s)
+	runHooks(r.onStart)
 }
 
 func (r *Routine) Restart() {
 	r.Reset()
+	r.log(r.logLevels.Restart, "routine restarted", nil)
+	r.measure(func(m *Metrics) {
+		m.restarts.Add(1)
+	})
 	r.Start()
 }
 
 func (r *Routine) End() {
 	if !r.started {
+		// Done routine doesn't tick anymore, but tick hooks are still called, so it can be restarted from them
+		if r.Done() {
+			runHooks(r.onTick)
+		}
+		return
+	}
+	defer r.nextTick()
+
+	if !r.running() {
 		return
 	}
 
@@ -36,18 +56,24 @@ func (r *Routine) End() {
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepEnd)
 	if !r.isPrevExecuted(caller) {
+		r.skip(s)
 		return
 	}
+	r.reach(s)
 	r.addExecution(caller)
 	r.markAsExecuted(caller)
 
 	r.started = false
 	r.completed = true
+	r.finish(s)
+	r.log(r.logLevels.Complete, "routine completed", nil)
+	runHooks(r.onEnd)
 }
 
 func (r *Routine) Do(action func()) {
-	if !r.started {
+	if !r.running() {
 		return
 	}
 
@@ -57,17 +83,21 @@ func (r *Routine) Do(action func()) {
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepDo)
 	if !r.isPrevExecuted(caller) {
+		r.skip(s)
 		return
 	}
+	r.reach(s)
 	r.addExecution(caller)
 	r.markAsExecuted(caller)
 
-	action()
+	r.run(caller, s, r.measureAction(action))
+	r.finish(s)
 }
 
 func (r *Routine) Func(action func()) {
-	if !r.started {
+	if !r.running() {
 		return
 	}
 
@@ -77,20 +107,24 @@ func (r *Routine) Func(action func()) {
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepFunc)
 	if !r.isPrevExecutedTo(r.executionSequenceIndex(caller)) {
+		r.skip(s)
 		return
 	}
+	r.reachScope(caller, s)
 
-	action()
+	r.run(caller, s, action)
 
-	if r.isPrevExecuted(caller) {
+	if r.running() && r.isPrevExecuted(caller) {
 		r.addExecution(caller)
 		r.markAsExecuted(caller)
+		r.finish(s)
 	}
 }
 
 func (r *Routine) Loop(start, end int, action func(i int)) {
-	if !r.started {
+	if !r.running() {
 		return
 	}
 
@@ -100,24 +134,31 @@ func (r *Routine) Loop(start, end int, action func(i int)) {
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepLoop)
 	if !r.isPrevExecutedTo(r.executionSequenceIndex(caller)) {
+		r.skip(s)
 		return
 	}
+	r.reachScope(caller, s)
 
-	for i := start; i < end; i++ {
-		_, popIndex := r.pushToStack(uintptr(i))
-		action(i)
-		popIndex()
-	}
+	r.run(caller, s, func() {
+		for i := start; i < end; i++ {
+			iteration, popIndex := r.pushToStack(uintptr(i))
+			r.iterate(s, iteration, i)
+			action(i)
+			popIndex()
+		}
+	})
 
-	if r.isPrevExecuted(caller) {
+	if r.running() && r.isPrevExecuted(caller) {
 		r.addExecution(caller)
 		r.markAsExecuted(caller)
+		r.finish(s)
 	}
 }
 
 func (r *Routine) Repeat(n int, action func()) {
-	if !r.started {
+	if !r.running() {
 		return
 	}
 
@@ -127,18 +168,86 @@ func (r *Routine) Repeat(n int, action func()) {
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepRepeat)
 	if !r.isPrevExecutedTo(r.executionSequenceIndex(caller)) {
+		r.skip(s)
 		return
 	}
+	r.reachScope(caller, s)
 
-	for i := 0; i < n; i++ {
-		_, popIndex := r.pushToStack(uintptr(i))
-		action()
-		popIndex()
+	r.run(caller, s, func() {
+		for i := 0; i < n; i++ {
+			iteration, popIndex := r.pushToStack(uintptr(i))
+			r.iterate(s, iteration, i)
+			action()
+			popIndex()
+		}
+	})
+
+	if r.running() && r.isPrevExecuted(caller) {
+		r.addExecution(caller)
+		r.markAsExecuted(caller)
+		r.finish(s)
+	}
+}
+
+// WithTimeout runs body until all its steps are executed, if deadline passes before that, remaining steps of body
+// are abandoned and onTimeout runs instead
+func (r *Routine) WithTimeout(duration time.Duration, body func(), onTimeout func()) {
+	if !r.running() {
+		return
 	}
 
-	if r.isPrevExecuted(caller) {
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepWithTimeout)
+	if !r.isPrevExecutedTo(r.executionSequenceIndex(caller)) {
+		r.skip(s)
+		return
+	}
+	r.reachScope(caller, s)
+
+	// Timer starts only when block is reached, so waits before it don't count towards its timeout
+	t, found := r.timers[caller]
+	if !found && r.isPrevExecuted(caller) {
+		now := r.now()
+		t = timer{
+			start:    now,
+			deadline: now.Add(duration),
+		}
+		r.timers[caller] = t
+		found = true
+	}
+
+	_, timedOut := r.values[caller]
+	if !timedOut && found && r.isPassed(t.deadline) {
+		timedOut = true
+		r.values[caller] = struct{}{}
+		r.abandon(0)
+	}
+
+	r.run(caller, s, func() {
+		block, action := uintptr(0), body
+		if timedOut {
+			block, action = 1, onTimeout
+		}
+
+		_, popBlock := r.pushToStack(block)
+		action()
+		popBlock()
+	})
+
+	if r.running() && r.isPrevExecuted(caller) {
 		r.addExecution(caller)
 		r.markAsExecuted(caller)
+		if timedOut {
+			r.timeout(s)
+		} else {
+			r.finish(s)
+		}
 	}
 }
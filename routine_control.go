@@ -0,0 +1,107 @@
+package routines
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Pause stops execution of steps and freezes timers of waiters until Resume
+func (r *Routine) Pause() {
+	if r.paused {
+		return
+	}
+
+	r.clockTime = r.now()
+	r.paused = true
+}
+
+func (r *Routine) Resume() {
+	if !r.paused {
+		return
+	}
+
+	r.clockWall = r.clock.Now()
+	r.paused = false
+}
+
+func (r *Routine) Paused() bool {
+	return r.paused
+}
+
+// SkipWait completes currently active waiter on the next tick
+func (r *Routine) SkipWait() {
+	r.skipWait = true
+}
+
+// SkipAll enables fast mode, every time-based wait is treated as elapsed while actions still run in order,
+// fast mode stays enabled until disabled or routine is reset
+func (r *Routine) SkipAll() {
+	r.SetFastMode(true)
+}
+
+func (r *Routine) SetFastMode(enabled bool) {
+	r.fastMode = enabled
+}
+
+func (r *Routine) FastMode() bool {
+	return r.fastMode
+}
+
+func (r *Routine) consumeSkipWait() bool {
+	if !r.skipWait {
+		return false
+	}
+
+	r.skipWait = false
+	return true
+}
+
+func (r *Routine) consumeSkipCondition() bool {
+	if !r.skipConditions {
+		return false
+	}
+	return r.consumeSkipWait()
+}
+
+// SetTimeScale changes how fast time passes for waiters relative to real time, scale 2 makes waits twice as short,
+// already started waits keep elapsed part of their time, scale must be finite and not negative
+func (r *Routine) SetTimeScale(scale float64) error {
+	if scale < 0 || math.IsNaN(scale) || math.IsInf(scale, 0) {
+		return fmt.Errorf("invalid time scale: %v", scale)
+	}
+
+	if !r.paused {
+		r.clockTime = r.now()
+		r.clockWall = r.clock.Now()
+	}
+	r.timeScale = scale
+	return nil
+}
+
+func (r *Routine) TimeScale() float64 {
+	return r.timeScale
+}
+
+// Routine has its own time that passes only when routine is not paused, all timers of waiters use it
+func (r *Routine) now() time.Time {
+	if r.paused {
+		return r.clockTime
+	}
+	return r.clockTime.Add(r.scaledDuration(r.clock.Now().Sub(r.clockWall)))
+}
+
+func (r *Routine) scaledDuration(elapsed time.Duration) time.Duration {
+	if r.timeScale == 1 {
+		return elapsed
+	}
+	return time.Duration(float64(elapsed) * r.timeScale)
+}
+
+// Real duration is known only if time passes, so scale must be positive
+func (r *Routine) realDuration(scaled time.Duration) time.Duration {
+	if r.timeScale == 1 {
+		return scaled
+	}
+	return time.Duration(float64(scaled) / r.timeScale)
+}
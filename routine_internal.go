@@ -2,7 +2,9 @@ package routines
 
 import (
 	"fmt"
+	"math"
 	"runtime"
+	"strings"
 	"time"
 )
 
@@ -14,6 +16,10 @@ func (r *Routine) caller() uintptr {
 	return r.pc[0]
 }
 
+func (r *Routine) running() bool {
+	return r.started && !r.suspended && !r.paused
+}
+
 func (r *Routine) isExecuted(callers string) (executed bool) {
 	_, executed = r.executed[callers]
 	return executed
@@ -68,15 +74,128 @@ func (r *Routine) markAsExecuted(caller string) {
 	r.executed[caller] = struct{}{}
 }
 
-func (r *Routine) executionTimer(caller string, duration time.Duration) <-chan time.Time {
-	if timer, found := r.timers[caller]; found {
-		return timer
+// Timer is in routine time, unless it's a wall timer of absolute waits
+type timer struct {
+	start    time.Time
+	deadline time.Time
+	wall     bool
+}
+
+func (r *Routine) executionTimer(caller string, duration time.Duration) time.Time {
+	if t, found := r.timers[caller]; found {
+		return t.deadline
 	}
 
-	timer := time.After(duration)
-	r.timers[caller] = timer
+	now := r.now()
+	r.timers[caller] = timer{
+		start:    now,
+		deadline: now.Add(duration),
+	}
 	r.addExecution(caller)
-	return timer
+	return now.Add(duration)
+}
+
+// Aligned timer starts at the deadline of the previous timed wait if it's not older than one period, otherwise timer
+// is re-anchored to now, so late waits don't finish right away one after another
+func (r *Routine) alignedExecutionTimer(caller string, duration time.Duration) time.Time {
+	if _, found := r.timers[caller]; !found && !r.lastDeadline.IsZero() && r.now().Sub(r.lastDeadline) <= duration {
+		r.timers[caller] = timer{
+			start:    r.lastDeadline,
+			deadline: r.lastDeadline.Add(duration),
+		}
+		r.addExecution(caller)
+	}
+	return r.executionTimer(caller, duration)
+}
+
+// Skipped waits are aligned to the moment they finished, not to the deadline they didn't reach
+func (r *Routine) alignTo(deadline time.Time) {
+	if now := r.now(); now.Before(deadline) {
+		deadline = now
+	}
+	r.lastDeadline = deadline
+}
+
+func (r *Routine) wallTimer(caller string, deadline func() time.Time) time.Time {
+	if t, found := r.timers[caller]; found {
+		return t.deadline
+	}
+
+	r.timers[caller] = timer{
+		start:    r.clock.Now(),
+		deadline: deadline(),
+		wall:     true,
+	}
+	r.addExecution(caller)
+	return r.timers[caller].deadline
+}
+
+func (r *Routine) isWallExpired(deadline time.Time) bool {
+	return r.fastMode || !r.clock.Now().Before(deadline)
+}
+
+func (r *Routine) isExpired(deadline time.Time) bool {
+	return r.fastMode || r.isPassed(deadline)
+}
+
+// Passed deadline is not affected by fast mode, so waits elapsed by it can be told from timed out ones
+func (r *Routine) isPassed(deadline time.Time) bool {
+	return !r.now().Before(deadline)
+}
+
+// Waits for conditions are checked at least this often when routine is idle
+const pollInterval = time.Millisecond
+
+// Idle duration is time left to the closest deadline of pending steps, it's zero if nothing is pending
+func (r *Routine) idleDuration() time.Duration {
+	idle := time.Duration(math.MaxInt64)
+	for caller, s := range r.steps {
+		if !s.reached || r.isExecuted(caller) {
+			continue
+		}
+
+		if t, found := r.timers[caller]; found && !r.paused && r.timeScale > 0 {
+			remaining := r.realDuration(t.deadline.Sub(r.now()))
+			if t.wall {
+				remaining = t.deadline.Sub(r.clock.Now())
+			}
+			idle = min(idle, max(remaining, 0))
+			if s.kind.isTimed() {
+				continue
+			}
+		}
+		if s.kind.IsWait() {
+			idle = min(idle, pollInterval)
+		}
+	}
+
+	if idle == math.MaxInt64 {
+		return 0
+	}
+	return idle
+}
+
+func (r *Routine) nextTick() {
+	r.tick++
+	r.measure(func(m *Metrics) {
+		m.ticks.Add(1)
+	})
+
+	r.skipWait = false
+	runHooks(r.onTick)
+}
+
+// Abandon marks all steps of block inside current step as executed, so steps after them can continue
+func (r *Routine) abandon(block uintptr) {
+	_, pop := r.pushToStack(block)
+	prefix := encodeCaller(r.executionStack)
+	pop()
+
+	for _, c := range r.executionSequence {
+		if strings.HasPrefix(c, prefix) {
+			r.markAsExecuted(c)
+		}
+	}
 }
 
 func (r *Routine) pushToStack(caller uintptr) (string, func()) {
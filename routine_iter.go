@@ -0,0 +1,128 @@
+package routines
+
+import "iter"
+
+// ForEach calls action for each value of sequence, values are pulled lazily, next value is pulled only when steps of
+// previous iterations are executed
+func ForEach[T any](r *Routine, seq iter.Seq[T], action func(v T)) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepForEach)
+	if !r.isPrevExecutedTo(r.executionSequenceIndex(caller)) {
+		r.skip(s)
+		return
+	}
+	r.reachScope(caller, s)
+
+	values := executionValues(r, caller, func() *pulled[T] {
+		next, stop := iter.Pull(seq)
+		return &pulled[T]{next: next, stop: stop}
+	})
+	forEach(r, caller, s, values, action)
+}
+
+type pair[K, V any] struct {
+	key   K
+	value V
+}
+
+// ForEach2 calls action for each key-value of sequence, pairs are pulled lazily like values of ForEach
+func ForEach2[K, V any](r *Routine, seq iter.Seq2[K, V], action func(k K, v V)) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepForEach)
+	if !r.isPrevExecutedTo(r.executionSequenceIndex(caller)) {
+		r.skip(s)
+		return
+	}
+	r.reachScope(caller, s)
+
+	pairs := executionValues(r, caller, func() *pulled[pair[K, V]] {
+		next, stop := iter.Pull2(seq)
+		return &pulled[pair[K, V]]{
+			next: func() (pair[K, V], bool) {
+				k, v, ok := next()
+				return pair[K, V]{key: k, value: v}, ok
+			},
+			stop: stop,
+		}
+	})
+	forEach(r, caller, s, pairs, func(p pair[K, V]) {
+		action(p.key, p.value)
+	})
+}
+
+// Pulled keeps values of sequence pulled so far, sequence is stopped when it's over or when routine is reset
+type pulled[T any] struct {
+	values []T
+	next   func() (T, bool)
+	stop   func()
+	done   bool
+}
+
+func (p *pulled[T]) pull() bool {
+	if p.done {
+		return false
+	}
+
+	value, ok := p.next()
+	if !ok {
+		p.release()
+		return false
+	}
+	p.values = append(p.values, value)
+	return true
+}
+
+func (p *pulled[T]) release() {
+	p.done = true
+	p.stop()
+}
+
+func forEach[T any](r *Routine, caller string, s *step, values *pulled[T], action func(v T)) {
+	r.run(caller, s, func() {
+		for i := 0; ; i++ {
+			iteration, popIndex := r.pushToStack(uintptr(i))
+			if i == len(values.values) && !(r.running() && r.isPrevExecuted(iteration) && values.pull()) {
+				popIndex()
+				return
+			}
+
+			r.iterate(s, iteration, i)
+			action(values.values[i])
+			popIndex()
+		}
+	})
+
+	if r.running() && values.done && r.isPrevExecuted(caller) {
+		r.addExecution(caller)
+		r.markAsExecuted(caller)
+		r.finish(s)
+	}
+}
+
+func executionValues[T any](r *Routine, caller string, collect func() T) T {
+	if value, found := r.values[caller]; found {
+		return value.(T)
+	}
+
+	value := collect()
+	r.values[caller] = value
+	return value
+}
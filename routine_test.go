@@ -1,6 +1,10 @@
 package routines_test
 
 import (
+	"iter"
+	"math"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +14,7 @@ import (
 
 const maxLoop = 400
 const waitTime = time.Millisecond
+const maxDuration = time.Second
 
 func TestRoutine_StartEnd(t *testing.T) {
 	r := routines.NewRoutine()
@@ -543,3 +548,526 @@ func TestRoutine_Repeat(t *testing.T) {
 	test.True(t, loops < maxLoop)
 	test.True(t, e1 && e2 && e3 == 2 && e4 == 6 && e5 == 2)
 }
+
+func TestForEach(t *testing.T) {
+	r := routines.StartRoutine()
+
+	iterations := 0
+	seq := func(yield func(string) bool) {
+		iterations++
+		for _, v := range []string{"a", "b", "c"} {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+
+	var e1 []string
+	var e2 []string
+
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		routines.ForEach(r, iter.Seq[string](seq), func(v string) {
+			r.Do(func() {
+				e1 = append(e1, v)
+			})
+			r.WaitFor(waitTime)
+		})
+
+		routines.ForEach2(r, slices.All([]string{"x", "y"}), func(i int, v string) {
+			r.WaitFor(waitTime)
+			r.Do(func() {
+				test.Equal(t, len(e2), i)
+				e2 = append(e2, v)
+			})
+		})
+
+		r.End()
+	}
+
+	test.True(t, r.Completed())
+	test.Equal(t, iterations, 1)
+	test.EqualEl(t, e1, []string{"a", "b", "c"})
+	test.EqualEl(t, e2, []string{"x", "y"})
+}
+
+func TestForEach_Lazy(t *testing.T) {
+	r := routines.StartRoutine()
+
+	pulled, stopped := 0, false
+	seq := func(yield func(int) bool) {
+		defer func() {
+			stopped = true
+		}()
+		for i := 0; ; i++ {
+			pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	var values []int
+	for i := 0; i < 3; i++ {
+		routines.ForEach(r, seq, func(v int) {
+			r.Do(func() {
+				values = append(values, v)
+			})
+			r.WaitUntil(func() bool {
+				return i > v
+			})
+		})
+		r.End()
+	}
+
+	test.Equal(t, pulled, 3)
+	test.EqualEl(t, values, []int{0, 1, 2})
+	test.False(t, r.Completed())
+	test.False(t, stopped)
+
+	r.Reset()
+	test.True(t, stopped)
+}
+
+func TestGenerator(t *testing.T) {
+	e1 := 0
+
+	g := routines.NewGenerator(func(g *routines.Generator[int]) {
+		g.Yield(1)
+		g.Do(func() {
+			e1++
+		})
+		g.WaitFor(waitTime)
+		g.Repeat(2, func() {
+			g.Yield(2)
+		})
+		g.Do(func() {
+			e1++
+		})
+		g.Yield(3)
+	})
+
+	v, ok := g.Tick()
+	test.True(t, ok)
+	test.Equal(t, v, 1)
+	test.Equal(t, e1, 0)
+
+	v, ok = g.Next()
+	test.True(t, ok)
+	test.Equal(t, v, 2)
+	test.Equal(t, e1, 1)
+
+	var rest []int
+	for v := range g.All() {
+		rest = append(rest, v)
+	}
+	test.EqualEl(t, rest, []int{2, 3})
+	test.Equal(t, e1, 2)
+	test.True(t, g.Completed())
+
+	_, ok = g.Next()
+	test.False(t, ok)
+
+	g.Reset()
+	next, stop := iter.Pull(g.All())
+	defer stop()
+
+	v, ok = next()
+	test.True(t, ok)
+	test.Equal(t, v, 1)
+}
+
+func TestGenerator_NextIdle(t *testing.T) {
+	ticks := 0
+	g := routines.NewGenerator(func(g *routines.Generator[int]) {
+		g.WaitFor(waitTime * 20)
+		g.Yield(1)
+	})
+	g.OnTick(func() {
+		ticks++
+	})
+
+	start := time.Now()
+	v, ok := g.Next()
+	test.True(t, ok)
+	test.Equal(t, v, 1)
+	test.True(t, time.Since(start) >= waitTime*20)
+	test.True(t, ticks < 5)
+}
+
+func TestRoutine_Steps(t *testing.T) {
+	r := routines.StartRoutine(routines.WithSteps())
+	test.Equal(t, len(r.Steps()), 1)
+
+	r.Do(func() {})
+	r.WaitFor(time.Hour)
+	r.WaitUntil(func() bool { return true })
+	r.End()
+
+	steps := r.Steps()
+	test.Equal(t, len(steps), 5)
+
+	test.Equal(t, steps[1].Kind, routines.StepDo)
+	test.Equal(t, steps[1].Status, routines.StatusDone)
+	test.True(t, strings.HasSuffix(steps[1].File, "routine_test.go"))
+
+	wait := steps[2]
+	test.Equal(t, wait.Kind, routines.StepWaitFor)
+	test.Equal(t, wait.Status, routines.StatusWaiting)
+	test.Equal(t, wait.Line, steps[1].Line+1)
+	test.True(t, wait.Remaining > time.Hour-time.Minute && wait.Remaining <= time.Hour)
+	test.True(t, time.Until(wait.Deadline) <= wait.Remaining)
+
+	test.Equal(t, steps[3].Kind, routines.StepWaitUntil)
+	test.Equal(t, steps[3].Status, routines.StatusPending)
+	test.True(t, steps[3].Deadline.IsZero())
+
+	test.Equal(t, steps[4].Kind, routines.StepEnd)
+	test.Equal(t, steps[4].Status, routines.StatusPending)
+}
+
+func TestRoutine_ScopeReached(t *testing.T) {
+	r := routines.StartRoutine(routines.WithSteps())
+	waiting := false
+	start := time.Now()
+	for i := 0; !r.Completed() && time.Since(start) < maxDuration; i++ {
+		r.WaitFor(waitTime)
+		r.Func(func() {
+			r.WaitFor(waitTime)
+		})
+		r.End()
+
+		if i == 0 {
+			test.Equal(t, r.Steps()[2].Kind, routines.StepFunc)
+			test.Equal(t, r.Steps()[2].Status, routines.StatusPending)
+		}
+		waiting = waiting || r.Steps()[2].Status == routines.StatusWaiting
+	}
+
+	test.True(t, r.Completed())
+	test.True(t, waiting)
+}
+
+func TestRoutine_Progress(t *testing.T) {
+	r := routines.NewRoutine()
+	test.Equal(t, r.Progress(), 0.0)
+
+	r.Start()
+
+	progress, partial := 0.0, false
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.Do(func() {})
+		r.Repeat(2, func() {
+			r.WaitFor(waitTime * 10)
+		})
+		r.Do(func() {})
+		r.End()
+
+		p := r.Progress()
+		test.True(t, p >= progress)
+		test.True(t, p < 1 || r.Completed())
+		partial = partial || p > 0 && p < 1
+		progress = p
+	}
+
+	test.True(t, r.Completed())
+	test.True(t, partial)
+	test.Equal(t, r.Progress(), 1.0)
+
+	r.Reset()
+	test.Equal(t, r.Progress(), 0.0)
+}
+
+func TestRoutine_Pause(t *testing.T) {
+	r := routines.StartRoutine(routines.WithSteps())
+	test.False(t, r.Paused())
+
+	const wait = waitTime * 20
+	e1 := 0
+	tick := func() {
+		r.WaitFor(wait)
+		r.Do(func() {
+			e1++
+		})
+		r.End()
+	}
+
+	start := time.Now()
+	tick()
+	r.Pause()
+	test.True(t, r.Paused())
+
+	remaining := r.Steps()[1].Remaining
+	test.True(t, remaining > 0 && remaining <= wait)
+
+	time.Sleep(wait * 2)
+	tick()
+	test.Equal(t, e1, 0)
+	test.False(t, r.Completed())
+	test.Equal(t, r.Steps()[1].Remaining, remaining)
+	test.Equal(t, r.Steps()[1].Status, routines.StatusWaiting)
+
+	r.Resume()
+	test.False(t, r.Paused())
+	for !r.Completed() && time.Since(start) < maxDuration {
+		tick()
+	}
+
+	test.True(t, r.Completed())
+	test.Equal(t, e1, 1)
+	test.True(t, time.Since(start) >= wait*3)
+}
+
+func TestRoutine_SetTimeScale(t *testing.T) {
+	const wait = waitTime * 100
+
+	r := routines.StartRoutine(routines.WithTimeScale(10), routines.WithSteps())
+	test.Equal(t, r.TimeScale(), 10.0)
+
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.WaitFor(wait)
+		r.End()
+	}
+	test.True(t, r.Completed())
+	test.True(t, time.Since(start) < wait/2)
+
+	r = routines.StartRoutine(routines.WithSteps())
+	start = time.Now()
+	for i := 0; !r.Completed() && time.Since(start) < maxDuration; i++ {
+		r.WaitFor(wait)
+		r.End()
+
+		switch i {
+		case 0:
+			r.SetTimeScale(0)
+			time.Sleep(wait / 10)
+		case 1:
+			remaining := r.Steps()[1].Remaining
+			test.True(t, remaining > wait/2)
+			test.True(t, r.Steps()[1].Deadline.IsZero())
+
+			r.Pause()
+			r.SetTimeScale(100)
+			time.Sleep(wait / 10)
+			test.Equal(t, r.Steps()[1].Remaining, remaining/100)
+			r.Resume()
+			start = time.Now()
+		}
+	}
+	test.True(t, r.Completed())
+	test.True(t, time.Since(start) < wait/2)
+
+	test.True(t, r.SetTimeScale(-1) != nil)
+	test.True(t, r.SetTimeScale(math.NaN()) != nil)
+	test.Equal(t, r.TimeScale(), 100.0)
+}
+
+func TestRoutine_SkipWait(t *testing.T) {
+	for _, skippable := range []bool{true, false} {
+		r := routines.StartRoutine(routines.WithSkippableConditions(skippable))
+
+		e1, e2 := 0, 0
+		done := false
+		for i := 0; i < 6; i++ {
+			r.WaitFor(time.Hour)
+			r.Do(func() {
+				e1++
+			})
+			r.WaitUntil(func() bool {
+				return done
+			})
+			r.Do(func() {
+				e2++
+			})
+			r.End()
+
+			if i < 4 {
+				r.SkipWait()
+				continue
+			}
+
+			test.Equal(t, e1, 1)
+			if i == 4 && !skippable {
+				test.Equal(t, e2, 0)
+				test.False(t, r.Completed())
+				done = true
+			}
+		}
+
+		test.Equal(t, e2, 1)
+		test.True(t, r.Completed())
+	}
+}
+
+func TestRoutine_SkipAll(t *testing.T) {
+	metrics := routines.NewMetrics()
+	r := routines.StartRoutine(routines.WithMetrics(metrics))
+	test.False(t, r.FastMode())
+
+	r.SkipAll()
+	test.True(t, r.FastMode())
+
+	var order []int
+	done := false
+	tick := func() {
+		r.WaitFor(time.Hour)
+		r.Do(func() {
+			order = append(order, 1)
+		})
+		r.WaitUntilOrTimeout(func() bool {
+			return false
+		}, time.Hour)
+		r.Do(func() {
+			order = append(order, 2)
+		})
+		r.WaitUntil(func() bool {
+			return done
+		})
+		r.Do(func() {
+			order = append(order, 3)
+		})
+		r.End()
+	}
+
+	for i := 0; i < 4; i++ {
+		tick()
+	}
+	test.EqualEl(t, order, []int{1, 2})
+	test.False(t, r.Completed())
+
+	done = true
+	tick()
+	test.EqualEl(t, order, []int{1, 2, 3})
+	test.True(t, r.Completed())
+	test.Equal(t, metrics.Snapshot().Timeouts, 0)
+
+	r.Reset()
+	test.False(t, r.FastMode())
+}
+
+func TestRoutine_WithTimeout(t *testing.T) {
+	const wait = waitTime * 10
+
+	for _, timeout := range []bool{true, false} {
+		r := routines.StartRoutine(routines.WithSteps())
+
+		innerWait := time.Duration(0)
+		if timeout {
+			innerWait = time.Hour
+		}
+
+		e1, e2, e3, f1, f2 := 0, 0, 0, 0, 0
+		start := time.Now()
+		for !r.Completed() && time.Since(start) < maxDuration {
+			r.WithTimeout(wait, func() {
+				r.Do(func() {
+					e1++
+				})
+				r.Repeat(2, func() {
+					r.WaitFor(innerWait)
+				})
+				r.Do(func() {
+					e2++
+				})
+			}, func() {
+				r.Do(func() {
+					f1++
+				})
+				r.WaitFor(waitTime)
+				r.Do(func() {
+					f2++
+				})
+			})
+			r.Do(func() {
+				e3++
+			})
+			r.End()
+		}
+
+		test.True(t, r.Completed())
+		test.Equal(t, e1, 1)
+		test.Equal(t, e3, 1)
+		test.Equal(t, r.Steps()[1].Kind, routines.StepWithTimeout)
+		test.Equal(t, r.Steps()[1].Status, routines.StatusDone)
+
+		if timeout {
+			test.Equal(t, e2, 0)
+			test.Equal(t, f1, 1)
+			test.Equal(t, f2, 1)
+			test.True(t, time.Since(start) >= wait)
+		} else {
+			test.Equal(t, e2, 1)
+			test.Equal(t, f1, 0)
+			test.Equal(t, f2, 0)
+			test.True(t, time.Since(start) < wait)
+		}
+	}
+}
+
+func TestRoutine_WithTimeout_AfterWait(t *testing.T) {
+	r := routines.StartRoutine()
+
+	e1, f1 := 0, 0
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.WaitFor(waitTime * 50)
+		r.WithTimeout(waitTime*20, func() {
+			r.Do(func() {
+				e1++
+			})
+		}, func() {
+			r.Do(func() {
+				f1++
+			})
+		})
+		r.End()
+	}
+
+	test.True(t, r.Completed())
+	test.Equal(t, e1, 1)
+	test.Equal(t, f1, 0)
+}
+
+func TestRoutine_WaitForAligned(t *testing.T) {
+	tests := []struct {
+		name    string
+		aligned bool
+		work    time.Duration
+		elapsed time.Duration
+	}{
+		{name: "aligned", aligned: true, work: time.Millisecond * 300, elapsed: time.Millisecond * 5300},
+		{name: "not aligned", aligned: false, work: time.Millisecond * 300, elapsed: time.Millisecond * 6500},
+		{name: "re-anchored", aligned: true, work: time.Millisecond * 1500, elapsed: time.Millisecond * 12500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+			clock := &manualClock{now: start}
+			r := routines.StartRoutine(routines.WithClock(clock))
+
+			for i := 0; i < 1000 && !r.Completed(); i++ {
+				r.Repeat(5, func() {
+					if tt.aligned {
+						r.WaitForAligned(time.Second)
+					} else {
+						r.WaitFor(time.Second)
+					}
+					r.Do(func() {
+						clock.now = clock.now.Add(tt.work)
+					})
+				})
+				r.End()
+
+				if !r.Completed() {
+					clock.now = clock.now.Add(time.Millisecond * 100)
+				}
+			}
+
+			test.True(t, r.Completed())
+			test.Equal(t, clock.now.Sub(start), tt.elapsed)
+		})
+	}
+}
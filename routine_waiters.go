@@ -1,9 +1,12 @@
 package routines
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 func (r *Routine) WaitFor(duration time.Duration) {
-	if !r.started {
+	if !r.running() {
 		return
 	}
 
@@ -13,21 +16,120 @@ func (r *Routine) WaitFor(duration time.Duration) {
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepWaitFor)
 	if !r.isPrevExecuted(caller) {
+		r.skip(s)
 		return
 	}
+	r.reach(s)
 
-	timer := r.executionTimer(caller, duration)
-	select {
-	case <-timer:
-		r.markAsExecuted(caller)
-	default:
+	r.run(caller, s, func() {
+		deadline := r.executionTimer(caller, duration)
+		if r.isExpired(deadline) || r.consumeSkipWait() {
+			r.markAsExecuted(caller)
+			r.alignTo(deadline)
+			r.finish(s)
+		}
+	})
+}
+
+// WaitForAligned waits for time to pass since deadline of the previous WaitFor or WaitForAligned instead of since
+// the moment it's reached, so long sequences of waits don't drift
+func (r *Routine) WaitForAligned(duration time.Duration) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepWaitForAligned)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	r.run(caller, s, func() {
+		deadline := r.alignedExecutionTimer(caller, duration)
+		if r.isExpired(deadline) || r.consumeSkipWait() {
+			r.markAsExecuted(caller)
+			r.alignTo(deadline)
+			r.finish(s)
+		}
+	})
+}
+
+// WaitUntilTime waits until wall clock reaches t, time scale doesn't affect it
+func (r *Routine) WaitUntilTime(t time.Time) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepWaitUntilTime)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	r.run(caller, s, func() {
+		deadline := r.wallTimer(caller, func() time.Time {
+			return t
+		})
+		if r.isWallExpired(deadline) || r.consumeSkipWait() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+		}
+	})
+}
+
+// WaitForSchedule waits until the next time matching cron expression since the moment it's reached,
+// it panics if expression is invalid, see ParseSchedule for supported syntax
+func (r *Routine) WaitForSchedule(expr string) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepWaitForSchedule)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	r.run(caller, s, func() {
+		deadline := r.wallTimer(caller, func() time.Time {
+			schedule, err := ParseSchedule(expr)
+			if err != nil {
+				panic(fmt.Errorf("invalid schedule: %w", err))
+			}
+			return schedule.Next(r.clock.Now())
+		})
+		if !deadline.IsZero() && r.isWallExpired(deadline) || r.consumeSkipWait() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+		}
+	})
 }
 
 func (r *Routine) WaitUntil(condition func() bool) {
-	if !r.started {
+	if !r.running() {
 		return
 	}
 
@@ -37,18 +139,24 @@ func (r *Routine) WaitUntil(condition func() bool) {
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepWaitUntil)
 	if !r.isPrevExecuted(caller) {
+		r.skip(s)
 		return
 	}
+	r.reach(s)
 
 	r.addExecution(caller)
-	if condition() {
-		r.markAsExecuted(caller)
-	}
+	r.run(caller, s, func() {
+		if r.consumeSkipCondition() || condition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+		}
+	})
 }
 
 func (r *Routine) WaitUntilOrTimeout(condition func() bool, duration time.Duration) {
-	if !r.started {
+	if !r.running() {
 		return
 	}
 
@@ -58,23 +166,30 @@ func (r *Routine) WaitUntilOrTimeout(condition func() bool, duration time.Durati
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepWaitUntilOrTimeout)
 	if !r.isPrevExecuted(caller) {
+		r.skip(s)
 		return
 	}
+	r.reach(s)
 
-	timer := r.executionTimer(caller, duration)
-	select {
-	case <-timer:
-		r.markAsExecuted(caller)
-	default:
-		if condition() {
+	r.run(caller, s, func() {
+		deadline := r.executionTimer(caller, duration)
+		if r.isExpired(deadline) {
 			r.markAsExecuted(caller)
+			r.expire(s, deadline)
+			return
 		}
-	}
+
+		if r.consumeSkipCondition() || condition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+		}
+	})
 }
 
 func (r *Routine) WaitForDone(done <-chan struct{}) {
-	if !r.started {
+	if !r.running() {
 		return
 	}
 
@@ -84,21 +199,33 @@ func (r *Routine) WaitForDone(done <-chan struct{}) {
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepWaitForDone)
 	if !r.isPrevExecuted(caller) {
+		r.skip(s)
 		return
 	}
+	r.reach(s)
 
 	r.addExecution(caller)
-	select {
-	case <-done:
-		r.markAsExecuted(caller)
-	default:
-		return
-	}
+	r.run(caller, s, func() {
+		if r.consumeSkipCondition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+			return
+		}
+
+		select {
+		case <-done:
+			r.markAsExecuted(caller)
+			r.finish(s)
+		default:
+			return
+		}
+	})
 }
 
 func (r *Routine) WaitForDoneOrTimeout(done <-chan struct{}, duration time.Duration) {
-	if !r.started {
+	if !r.running() {
 		return
 	}
 
@@ -108,17 +235,33 @@ func (r *Routine) WaitForDoneOrTimeout(done <-chan struct{}, duration time.Durat
 	if r.isExecuted(caller) {
 		return
 	}
+	s := r.step(caller, StepWaitForDoneOrTimeout)
 	if !r.isPrevExecuted(caller) {
+		r.skip(s)
 		return
 	}
+	r.reach(s)
 
-	timer := r.executionTimer(caller, duration)
-	select {
-	case <-done:
-		r.markAsExecuted(caller)
-	case <-timer:
-		r.markAsExecuted(caller)
-	default:
-		return
-	}
+	r.run(caller, s, func() {
+		deadline := r.executionTimer(caller, duration)
+		if r.isExpired(deadline) {
+			r.markAsExecuted(caller)
+			r.expire(s, deadline)
+			return
+		}
+
+		if r.consumeSkipCondition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+			return
+		}
+
+		select {
+		case <-done:
+			r.markAsExecuted(caller)
+			r.finish(s)
+		default:
+			return
+		}
+	})
 }
@@ -0,0 +1,201 @@
+package routines
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	anyDOM   bool
+	anyDOW   bool
+	location *time.Location
+}
+
+type scheduleField struct {
+	name  string
+	low   int
+	high  int
+	names []string
+}
+
+var scheduleFields = [5]scheduleField{
+	{name: "minute", low: 0, high: 59},
+	{name: "hour", low: 0, high: 23},
+	{name: "day of month", low: 1, high: 31},
+	{name: "month", low: 1, high: 12, names: []string{
+		"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC",
+	}},
+	{name: "day of week", low: 0, high: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+var scheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses standard five-field cron expression: minute, hour, day of month, month and day of week.
+// Fields support *, lists, ranges, steps and names of months and days, macros like @daily are supported too.
+// Time zone can be set with CRON_TZ=<zone> or TZ=<zone> prefix, otherwise time zone of the checked time is used.
+func ParseSchedule(expr string) (*Schedule, error) {
+	s := &Schedule{}
+
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		zone, rest, _ := strings.Cut(expr, " ")
+		_, zone, _ = strings.Cut(zone, "=")
+
+		location, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("parse time zone: %w", err)
+		}
+		s.location = location
+		expr = strings.TrimSpace(rest)
+	}
+
+	if strings.HasPrefix(expr, "@") {
+		macro, found := scheduleMacros[expr]
+		if !found {
+			return nil, fmt.Errorf("unknown macro: %q", expr)
+		}
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(scheduleFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d in: %q", len(scheduleFields), len(fields), expr)
+	}
+
+	masks := [5]*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, field := range fields {
+		mask, err := parseScheduleField(field, scheduleFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", scheduleFields[i].name, err)
+		}
+		*masks[i] = mask
+	}
+
+	// Sunday can be both 0 and 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.anyDOM = strings.HasPrefix(fields[2], "*") || fields[2] == "?"
+	s.anyDOW = strings.HasPrefix(fields[4], "*") || fields[4] == "?"
+
+	return s, nil
+}
+
+func parseScheduleField(field string, f scheduleField) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepText)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step: %q", stepText)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangeText == "*" || rangeText == "?":
+			start, end = f.low, f.high
+		case strings.Contains(rangeText, "-"):
+			startText, endText, _ := strings.Cut(rangeText, "-")
+			var err error
+			if start, err = parseScheduleValue(startText, f); err != nil {
+				return 0, err
+			}
+			if end, err = parseScheduleValue(endText, f); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range: %q", rangeText)
+			}
+		default:
+			var err error
+			if start, err = parseScheduleValue(rangeText, f); err != nil {
+				return 0, err
+			}
+			end = start
+			if hasStep {
+				end = f.high
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			mask |= 1 << v
+		}
+	}
+	return mask, nil
+}
+
+func parseScheduleValue(text string, f scheduleField) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(text, name) {
+			return i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value: %q", text)
+	}
+	if v < f.low || v > f.high {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, f.low, f.high)
+	}
+	return v, nil
+}
+
+// Next returns the first time matching schedule strictly after t, zero time is returned if nothing matches in the
+// next five years
+func (s *Schedule) Next(t time.Time) time.Time {
+	location := t.Location()
+	if s.location != nil {
+		t = t.In(s.location)
+	}
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t.In(location)
+		}
+	}
+	return time.Time{}
+}
+
+// Day matches if any of restricted day fields matches, same as in standard cron
+func (s *Schedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.anyDOM || s.anyDOW {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,93 @@
+package routines_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func TestParseSchedule(t *testing.T) {
+	from := time.Date(2026, time.January, 1, 1, 58, 30, 0, time.UTC) // Thursday
+
+	tests := []struct {
+		expr string
+		next time.Time
+	}{
+		{expr: "* * * * *", next: time.Date(2026, time.January, 1, 1, 59, 0, 0, time.UTC)},
+		{expr: "0 2 * * *", next: time.Date(2026, time.January, 1, 2, 0, 0, 0, time.UTC)},
+		{expr: "@daily", next: time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", next: time.Date(2026, time.January, 1, 2, 0, 0, 0, time.UTC)},
+		{expr: "30 9-17/4 * * *", next: time.Date(2026, time.January, 1, 9, 30, 0, 0, time.UTC)},
+		{expr: "0 0 * * MON", next: time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", next: time.Date(2026, time.January, 4, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 13 * 5", next: time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 12 1,15 feb-mar *", next: time.Date(2026, time.February, 1, 12, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", next: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", next: time.Time{}},
+		{expr: "CRON_TZ=America/New_York 0 9 * * *", next: time.Date(2026, time.January, 1, 14, 0, 0, 0, time.UTC)},
+		{expr: "TZ=Asia/Kolkata 0 * * * *", next: time.Date(2026, time.January, 1, 2, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := routines.ParseSchedule(tt.expr)
+			test.Equal(t, err, nil)
+			test.True(t, schedule.Next(from).Equal(tt.next))
+		})
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *",
+		"@never", "TZ=Unknown/Zone * * * * *", "* * * FOO *"} {
+		_, err := routines.ParseSchedule(expr)
+		test.True(t, err != nil)
+	}
+}
+
+func TestRoutine_WaitForSchedule(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, time.January, 1, 1, 58, 30, 0, time.UTC)}
+	r := routines.StartRoutine(routines.WithClock(clock), routines.WithTimeScale(100), routines.WithSteps())
+
+	e1, e2 := 0, 0
+	for i := 0; i < 6; i++ {
+		r.WaitForSchedule("0 2 * * *")
+		r.Do(func() {
+			e1++
+		})
+		r.WaitUntilTime(time.Date(2026, time.January, 1, 3, 0, 0, 0, time.UTC))
+		r.Do(func() {
+			e2++
+		})
+		r.End()
+
+		switch i {
+		case 0:
+			test.Equal(t, r.Steps()[1].Deadline, time.Date(2026, time.January, 1, 2, 0, 0, 0, time.UTC))
+			test.Equal(t, r.Steps()[1].Remaining, time.Minute+time.Second*30)
+			clock.now = clock.now.Add(time.Minute)
+		case 1:
+			test.Equal(t, e1, 0)
+			clock.now = clock.now.Add(time.Second * 30)
+		case 2:
+			test.Equal(t, e1, 1)
+			test.Equal(t, e2, 0)
+			clock.now = clock.now.Add(time.Minute * 59)
+		case 3:
+			test.Equal(t, e2, 0)
+			clock.now = clock.now.Add(time.Minute)
+		}
+	}
+
+	test.Equal(t, e1, 1)
+	test.Equal(t, e2, 1)
+	test.True(t, r.Completed())
+}
@@ -0,0 +1,97 @@
+package routines
+
+import "time"
+
+// SelectCase is a case of Select, created by Case or Timeout
+type SelectCase struct {
+	receive   func() (func(), bool)
+	timeout   time.Duration
+	onTimeout func()
+}
+
+// Case is ready when value is received from channel or channel is closed, handler is called with received value
+func Case[T any](ch <-chan T, handler func(v T)) SelectCase {
+	return SelectCase{
+		receive: func() (func(), bool) {
+			select {
+			case v := <-ch:
+				return func() {
+					if handler != nil {
+						handler(v)
+					}
+				}, true
+			default:
+				return nil, false
+			}
+		},
+	}
+}
+
+// Timeout is ready when time passes since Select was reached, if there are several timeouts the shortest is used
+func Timeout(duration time.Duration, handler func()) SelectCase {
+	return SelectCase{
+		timeout:   duration,
+		onTimeout: handler,
+	}
+}
+
+// Select checks all cases in order each tick without blocking, handler of the first ready case is called once
+// and wait is done, timeout is checked only if no other case is ready
+func (r *Routine) Select(cases ...SelectCase) {
+	if !r.running() {
+		return
+	}
+
+	caller, pop := r.pushToStack(r.caller())
+	defer pop()
+
+	if r.isExecuted(caller) {
+		return
+	}
+	s := r.step(caller, StepSelect)
+	if !r.isPrevExecuted(caller) {
+		r.skip(s)
+		return
+	}
+	r.reach(s)
+
+	var timeout *SelectCase
+	for i, c := range cases {
+		if c.receive == nil && (timeout == nil || c.timeout < timeout.timeout) {
+			timeout = &cases[i]
+		}
+	}
+
+	r.addExecution(caller)
+	r.run(caller, s, func() {
+		for _, c := range cases {
+			if c.receive == nil {
+				continue
+			}
+
+			if handler, ready := c.receive(); ready {
+				r.markAsExecuted(caller)
+				r.measureAction(handler)()
+				r.finish(s)
+				return
+			}
+		}
+
+		if r.consumeSkipCondition() {
+			r.markAsExecuted(caller)
+			r.finish(s)
+			return
+		}
+
+		if timeout == nil {
+			return
+		}
+		if deadline := r.executionTimer(caller, timeout.timeout); r.isExpired(deadline) {
+			r.markAsExecuted(caller)
+			if timeout.onTimeout != nil {
+				r.measureAction(timeout.onTimeout)()
+			}
+			r.expire(s, deadline)
+		}
+	})
+}
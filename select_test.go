@@ -0,0 +1,76 @@
+package routines_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestRoutine_Select(t *testing.T) {
+	r := routines.StartRoutine(routines.WithSteps())
+
+	numbers, names := make(chan int, 1), make(chan string, 1)
+	var received []string
+	timeouts := 0
+	for i := 0; i < 6; i++ {
+		r.Select(
+			routines.Case(numbers, func(v int) {
+				received = append(received, "number")
+			}),
+			routines.Case(names, func(v string) {
+				received = append(received, v)
+			}),
+			routines.Timeout(time.Hour, func() {
+				timeouts++
+			}),
+		)
+		r.Select(
+			routines.Case(numbers, func(v int) {
+				test.Equal(t, v, 42)
+				received = append(received, "42")
+			}),
+		)
+		r.End()
+
+		switch i {
+		case 1:
+			names <- "name"
+		case 3:
+			numbers <- 42
+		}
+	}
+
+	test.EqualEl(t, received, []string{"name", "42"})
+	test.Equal(t, timeouts, 0)
+	test.True(t, r.Completed())
+	test.Equal(t, r.Steps()[1].Kind, routines.StepSelect)
+}
+
+func TestRoutine_Select_Timeout(t *testing.T) {
+	r := routines.StartRoutine()
+
+	never := make(chan struct{})
+	received, timeouts := 0, 0
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.Select(
+			routines.Case(never, func(struct{}) {
+				received++
+			}),
+			routines.Timeout(waitTime*20, func() {
+				timeouts += 10
+			}),
+			routines.Timeout(waitTime*5, func() {
+				timeouts++
+			}),
+		)
+		r.End()
+	}
+
+	test.True(t, r.Completed())
+	test.Equal(t, received, 0)
+	test.Equal(t, timeouts, 1)
+	test.True(t, time.Since(start) >= waitTime*5 && time.Since(start) < waitTime*20)
+}
@@ -0,0 +1,359 @@
+package routines
+
+import (
+	"log/slog"
+	"runtime"
+	"time"
+	"unsafe"
+)
+
+type StepKind int
+
+const (
+	StepStart StepKind = iota + 1
+	StepEnd
+	StepDo
+	StepFunc
+	StepLoop
+	StepRepeat
+	StepForEach
+	StepYield
+	StepWaitFor
+	StepWaitUntil
+	StepWaitUntilOrTimeout
+	StepWaitForDone
+	StepWaitForDoneOrTimeout
+	StepRetry
+	StepWithTimeout
+	StepEvery
+	StepInterval
+	StepWaitForAligned
+	StepWaitUntilTime
+	StepWaitForSchedule
+	StepChoose
+	StepWaitForRandom
+	StepWaitUntilEvery
+	StepWaitUntilEveryOrTimeout
+	StepWaitUntilBackoff
+	StepWaitUntilBackoffOrTimeout
+	StepWaitForChange
+	StepWaitForStable
+	StepWaitForCount
+	StepWaitForAll
+	StepWaitForAllOrTimeout
+	StepWaitForAny
+	StepWaitForAnyOrTimeout
+	StepWaitForQuorum
+	StepWaitForQuorumOrTimeout
+	StepSelect
+)
+
+var stepKindNames = map[StepKind]string{
+	StepStart:                     "Start",
+	StepEnd:                       "End",
+	StepDo:                        "Do",
+	StepFunc:                      "Func",
+	StepLoop:                      "Loop",
+	StepRepeat:                    "Repeat",
+	StepForEach:                   "ForEach",
+	StepYield:                     "Yield",
+	StepRetry:                     "Retry",
+	StepWithTimeout:               "WithTimeout",
+	StepEvery:                     "Every",
+	StepInterval:                  "Interval",
+	StepChoose:                    "Choose",
+	StepWaitFor:                   "WaitFor",
+	StepWaitForAligned:            "WaitForAligned",
+	StepWaitForRandom:             "WaitForRandom",
+	StepWaitUntilTime:             "WaitUntilTime",
+	StepWaitForSchedule:           "WaitForSchedule",
+	StepWaitUntil:                 "WaitUntil",
+	StepWaitUntilOrTimeout:        "WaitUntilOrTimeout",
+	StepWaitUntilEvery:            "WaitUntilEvery",
+	StepWaitUntilEveryOrTimeout:   "WaitUntilEveryOrTimeout",
+	StepWaitUntilBackoff:          "WaitUntilBackoff",
+	StepWaitUntilBackoffOrTimeout: "WaitUntilBackoffOrTimeout",
+	StepWaitForDone:               "WaitForDone",
+	StepWaitForDoneOrTimeout:      "WaitForDoneOrTimeout",
+	StepWaitForAll:                "WaitForAll",
+	StepWaitForAllOrTimeout:       "WaitForAllOrTimeout",
+	StepWaitForAny:                "WaitForAny",
+	StepWaitForAnyOrTimeout:       "WaitForAnyOrTimeout",
+	StepWaitForQuorum:             "WaitForQuorum",
+	StepWaitForQuorumOrTimeout:    "WaitForQuorumOrTimeout",
+	StepSelect:                    "Select",
+	StepWaitForChange:             "WaitForChange",
+	StepWaitForStable:             "WaitForStable",
+	StepWaitForCount:              "WaitForCount",
+}
+
+func (k StepKind) String() string {
+	return stepKindNames[k]
+}
+
+func (k StepKind) IsWait() bool {
+	switch k {
+	case StepWaitFor, StepWaitUntil, StepWaitUntilOrTimeout, StepWaitForDone, StepWaitForDoneOrTimeout,
+		StepWaitForAligned, StepWaitUntilTime, StepWaitForSchedule, StepWaitForRandom,
+		StepWaitUntilEvery, StepWaitUntilEveryOrTimeout, StepWaitUntilBackoff, StepWaitUntilBackoffOrTimeout,
+		StepWaitForChange, StepWaitForStable, StepWaitForCount,
+		StepWaitForAll, StepWaitForAllOrTimeout, StepWaitForAny, StepWaitForAnyOrTimeout,
+		StepWaitForQuorum, StepWaitForQuorumOrTimeout, StepSelect:
+		return true
+	default:
+		return false
+	}
+}
+
+// Timed waits depend only on time, so they don't need to be checked before their deadline
+func (k StepKind) isTimed() bool {
+	switch k {
+	case StepWaitFor, StepWaitForAligned, StepWaitForRandom, StepWaitUntilTime, StepWaitForSchedule:
+		return true
+	default:
+		return false
+	}
+}
+
+func (k StepKind) IsScope() bool {
+	switch k {
+	case StepFunc, StepLoop, StepRepeat, StepForEach, StepWithTimeout, StepChoose:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseStepKind(name string) (StepKind, bool) {
+	for kind, kindName := range stepKindNames {
+		if kindName == name {
+			return kind, true
+		}
+	}
+	return 0, false
+}
+
+type StepStatus int
+
+const (
+	StatusPending StepStatus = iota
+	StatusWaiting
+	StatusDone
+)
+
+var stepStatusNames = map[StepStatus]string{
+	StatusPending: "pending",
+	StatusWaiting: "waiting",
+	StatusDone:    "done",
+}
+
+func (s StepStatus) String() string {
+	return stepStatusNames[s]
+}
+
+// StepInfo describes known step, deadline and remaining time are set for timed steps, when time of routine is frozen by
+// zero time scale deadline is unknown and remaining time is measured in routine time
+type StepInfo struct {
+	ID        int
+	Parent    int
+	Kind      StepKind
+	File      string
+	Line      int
+	Status    StepStatus
+	Deadline  time.Time
+	Remaining time.Duration
+}
+
+type step struct {
+	id        int
+	kind      StepKind
+	parent    int
+	pc        uintptr
+	file      string
+	line      int
+	reached   bool
+	reachedAt time.Time
+	skipped   bool
+}
+
+func (s *step) location() (string, int) {
+	if s.file == "" {
+		frame, _ := runtime.CallersFrames([]uintptr{s.pc}).Next()
+		s.file, s.line = frame.File, frame.Line
+	}
+	return s.file, s.line
+}
+
+func (r *Routine) stepInfos() []StepInfo {
+	infos := make([]StepInfo, len(r.steps))
+	for caller, s := range r.steps {
+		infos[s.id-1] = r.stepInfo(caller, s)
+	}
+	return infos
+}
+
+func (r *Routine) stepInfo(caller string, s *step) StepInfo {
+	info := StepInfo{
+		ID:     s.id,
+		Parent: s.parent,
+		Kind:   s.kind,
+	}
+	info.File, info.Line = s.location()
+
+	switch {
+	case r.isExecuted(caller):
+		info.Status = StatusDone
+	case s.reached:
+		info.Status = StatusWaiting
+	default:
+		info.Status = StatusPending
+	}
+
+	if t, found := r.timers[caller]; found {
+		remaining := t.deadline.Sub(r.now())
+		if r.timeScale > 0 {
+			remaining = r.realDuration(remaining)
+			info.Deadline = r.clock.Now().Add(remaining)
+		}
+		if t.wall {
+			remaining = t.deadline.Sub(r.clock.Now())
+			info.Deadline = t.deadline
+		}
+		if info.Status != StatusDone {
+			info.Remaining = max(remaining, 0)
+		}
+	}
+
+	return info
+}
+
+// Steps are recorded only if something consumes them, otherwise step is nil and all bookkeeping is skipped
+func (r *Routine) step(caller string, kind StepKind) *step {
+	if !r.recordSteps && r.tracer == nil && r.logger == nil && len(r.metrics) == 0 && len(r.middlewares) == 0 {
+		return nil
+	}
+
+	if s, found := r.steps[caller]; found {
+		return s
+	}
+
+	s := &step{
+		id:     len(r.steps) + 1,
+		kind:   kind,
+		parent: r.parentStep(caller),
+		pc:     r.executionStack[len(r.executionStack)-1],
+	}
+	r.steps[caller] = s
+	return s
+}
+
+// Parent is the closest step that encloses caller, iteration indexes of loops are skipped
+func (r *Routine) parentStep(caller string) int {
+	size := int(unsafe.Sizeof(uintptr(0)))
+	for n := len(caller) - size; n > 0; n -= size {
+		if parent, found := r.steps[caller[:n]]; found {
+			return parent.id
+		}
+	}
+	return 0
+}
+
+func (r *Routine) skip(s *step) {
+	if s == nil || s.skipped {
+		return
+	}
+	s.skipped = true
+
+	r.trace(EventSkipped, s, 0)
+}
+
+func (r *Routine) reach(s *step) {
+	if s == nil || s.reached {
+		return
+	}
+	s.reached = true
+	s.reachedAt = r.now()
+
+	if s.kind.IsWait() {
+		r.trace(EventWaitStarted, s, 0)
+		r.log(r.logLevels.Step, "wait started", s)
+	} else {
+		r.trace(EventReached, s, 0)
+		r.log(r.logLevels.Step, "step reached", s)
+	}
+}
+
+// Scope is entered on each tick so its steps can be skipped, but it's reached only when steps before it are executed
+func (r *Routine) reachScope(caller string, s *step) {
+	if r.isPrevExecuted(caller) {
+		r.reach(s)
+	}
+}
+
+func (r *Routine) finish(s *step) {
+	if s == nil {
+		return
+	}
+
+	r.measureStep(s)
+	if s.kind.IsWait() {
+		r.trace(EventWaitFinished, s, 0)
+		r.log(r.logLevels.Step, "wait finished", s)
+	} else {
+		r.trace(EventExecuted, s, 0)
+		r.log(r.logLevels.Step, "step executed", s)
+	}
+}
+
+func (r *Routine) timeout(s *step) {
+	if s == nil {
+		return
+	}
+
+	r.measureStep(s)
+	r.measure(func(m *Metrics) {
+		m.timeouts.Add(1)
+	})
+	if s.kind.IsWait() {
+		r.trace(EventWaitFinished, s, 0)
+		r.log(r.logLevels.Timeout, "wait timed out", s)
+	} else {
+		r.trace(EventExecuted, s, 0)
+		r.log(r.logLevels.Timeout, "step timed out", s)
+	}
+}
+
+// Expired wait is timed out only if its deadline has passed, waits elapsed by fast mode are just finished
+func (r *Routine) expire(s *step, deadline time.Time) {
+	if r.isPassed(deadline) {
+		r.timeout(s)
+	} else {
+		r.finish(s)
+	}
+}
+
+func (r *Routine) fail(s *step, err error) {
+	r.started = false
+	r.err = err
+	r.measure(func(m *Metrics) {
+		m.failures.Add(1)
+	})
+	r.trace(EventFailed, s, 0)
+	r.log(r.logLevels.Fail, "routine failed", s, slog.Any("error", err))
+}
+
+// Iteration is traced once, when all steps before it are executed
+func (r *Routine) iterate(s *step, iteration string, i int) {
+	if r.tracer == nil {
+		return
+	}
+	if _, found := r.iterations[iteration]; found {
+		return
+	}
+	if !r.isPrevExecuted(iteration) {
+		return
+	}
+	r.iterations[iteration] = struct{}{}
+
+	r.trace(EventIteration, s, i)
+}
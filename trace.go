@@ -0,0 +1,223 @@
+package routines
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type EventKind int
+
+const (
+	EventReached EventKind = iota + 1
+	EventSkipped
+	EventExecuted
+	EventWaitStarted
+	EventWaitFinished
+	EventIteration
+	EventReset
+	EventFailed
+)
+
+var eventKindNames = map[EventKind]string{
+	EventReached:      "reached",
+	EventSkipped:      "skipped",
+	EventExecuted:     "executed",
+	EventWaitStarted:  "wait-started",
+	EventWaitFinished: "wait-finished",
+	EventIteration:    "iteration",
+	EventReset:        "reset",
+	EventFailed:       "failed",
+}
+
+func (k EventKind) String() string {
+	return eventKindNames[k]
+}
+
+type Event struct {
+	Time      time.Time
+	Tick      int
+	Kind      EventKind
+	Step      StepKind
+	ID        int
+	Parent    int
+	Iteration int
+	File      string
+	Line      int
+}
+
+// String returns event in trace line format:
+// <time> tick=<tick> <event> [step=<kind> id=<id> parent=<id>] [iteration=<i>] [at=<file>:<line>]
+func (e Event) String() string {
+	var b strings.Builder
+	b.WriteString(e.Time.Format(time.RFC3339Nano))
+	b.WriteString(" tick=")
+	b.WriteString(strconv.Itoa(e.Tick))
+	b.WriteString(" ")
+	b.WriteString(e.Kind.String())
+
+	if e.Step != 0 {
+		fmt.Fprintf(&b, " step=%s id=%d parent=%d", e.Step, e.ID, e.Parent)
+	}
+	if e.Kind == EventIteration {
+		fmt.Fprintf(&b, " iteration=%d", e.Iteration)
+	}
+	if e.File != "" {
+		fmt.Fprintf(&b, " at=%s:%d", e.File, e.Line)
+	}
+
+	return b.String()
+}
+
+func ParseEvent(line string) (Event, error) {
+	var event Event
+
+	timeText, rest, _ := strings.Cut(line, " ")
+	var err error
+	event.Time, err = time.Parse(time.RFC3339Nano, timeText)
+	if err != nil {
+		return Event{}, fmt.Errorf("parse time: %w", err)
+	}
+
+	for rest != "" {
+		var field string
+		if strings.HasPrefix(rest, "at=") {
+			field, rest = rest, ""
+		} else {
+			field, rest, _ = strings.Cut(rest, " ")
+		}
+
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			kind, ok := parseEventKind(field)
+			if !ok {
+				return Event{}, fmt.Errorf("unknown event: %q", field)
+			}
+			event.Kind = kind
+			continue
+		}
+
+		switch key {
+		case "tick":
+			event.Tick, err = strconv.Atoi(value)
+		case "step":
+			kind, ok := parseStepKind(value)
+			if !ok {
+				err = fmt.Errorf("unknown step: %q", value)
+			}
+			event.Step = kind
+		case "id":
+			event.ID, err = strconv.Atoi(value)
+		case "parent":
+			event.Parent, err = strconv.Atoi(value)
+		case "iteration":
+			event.Iteration, err = strconv.Atoi(value)
+		case "at":
+			i := strings.LastIndex(value, ":")
+			if i < 0 {
+				return Event{}, fmt.Errorf("invalid location: %q", value)
+			}
+			event.File = value[:i]
+			event.Line, err = strconv.Atoi(value[i+1:])
+		default:
+			err = fmt.Errorf("unknown field: %q", key)
+		}
+		if err != nil {
+			return Event{}, fmt.Errorf("parse %s: %w", key, err)
+		}
+	}
+
+	if event.Kind == 0 {
+		return Event{}, fmt.Errorf("no event in: %q", line)
+	}
+
+	return event, nil
+}
+
+func parseEventKind(name string) (EventKind, bool) {
+	for kind, kindName := range eventKindNames {
+		if kindName == name {
+			return kind, true
+		}
+	}
+	return 0, false
+}
+
+func ParseTrace(reader io.Reader) ([]Event, error) {
+	var events []Event
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		event, err := ParseEvent(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+type Tracer interface {
+	Trace(event Event)
+}
+
+type TracerFunc func(event Event)
+
+func (f TracerFunc) Trace(event Event) {
+	f(event)
+}
+
+type TraceWriter struct {
+	writer io.Writer
+	err    error
+}
+
+func NewTraceWriter(writer io.Writer) *TraceWriter {
+	return &TraceWriter{
+		writer: writer,
+	}
+}
+
+func (w *TraceWriter) Trace(event Event) {
+	if w.err != nil {
+		return
+	}
+
+	_, w.err = io.WriteString(w.writer, event.String()+"\n")
+}
+
+func (w *TraceWriter) Err() error {
+	return w.err
+}
+
+func (r *Routine) trace(kind EventKind, s *step, iteration int) {
+	if r.tracer == nil {
+		return
+	}
+
+	event := Event{
+		Time:      time.Now(),
+		Tick:      r.tick,
+		Kind:      kind,
+		Iteration: iteration,
+	}
+	if s != nil {
+		event.Step = s.kind
+		event.ID = s.id
+		event.Parent = s.parent
+		event.File, event.Line = s.location()
+	}
+
+	r.tracer.Trace(event)
+}
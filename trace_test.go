@@ -0,0 +1,114 @@
+package routines_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/routines"
+	"github.com/mymmrac/routines/internal/test"
+)
+
+func TestTrace(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := routines.NewTraceWriter(&buf)
+
+	r := routines.StartRoutine(routines.WithTracer(tracer))
+
+	start := time.Now()
+	for !r.Completed() && time.Since(start) < maxDuration {
+		r.Do(func() {})
+		r.Repeat(2, func() {
+			r.WaitFor(waitTime)
+		})
+		r.End()
+	}
+	r.Reset()
+
+	test.True(t, r.Completed() || !r.Started())
+	test.Equal(t, tracer.Err(), nil)
+
+	events, err := routines.ParseTrace(&buf)
+	test.Equal(t, err, nil)
+
+	type entry struct {
+		kind routines.EventKind
+		step routines.StepKind
+	}
+	var actual []entry
+	for _, event := range events {
+		if event.Kind == routines.EventSkipped {
+			continue
+		}
+		actual = append(actual, entry{kind: event.Kind, step: event.Step})
+	}
+
+	test.EqualEl(t, actual, []entry{
+		{kind: routines.EventReached, step: routines.StepStart},
+		{kind: routines.EventExecuted, step: routines.StepStart},
+		{kind: routines.EventReached, step: routines.StepDo},
+		{kind: routines.EventExecuted, step: routines.StepDo},
+		{kind: routines.EventReached, step: routines.StepRepeat},
+		{kind: routines.EventIteration, step: routines.StepRepeat},
+		{kind: routines.EventWaitStarted, step: routines.StepWaitFor},
+		{kind: routines.EventWaitFinished, step: routines.StepWaitFor},
+		{kind: routines.EventIteration, step: routines.StepRepeat},
+		{kind: routines.EventWaitStarted, step: routines.StepWaitFor},
+		{kind: routines.EventWaitFinished, step: routines.StepWaitFor},
+		{kind: routines.EventExecuted, step: routines.StepRepeat},
+		{kind: routines.EventReached, step: routines.StepEnd},
+		{kind: routines.EventExecuted, step: routines.StepEnd},
+		{kind: routines.EventReset},
+	})
+
+	for _, event := range events {
+		if event.Kind == routines.EventReset || event.Step == routines.StepStart {
+			continue
+		}
+		test.True(t, strings.HasSuffix(event.File, "trace_test.go"))
+		test.True(t, event.Line > 0)
+		test.True(t, event.ID > 0)
+		if event.Step == routines.StepWaitFor {
+			test.True(t, event.Parent > 0)
+		}
+	}
+	test.True(t, events[len(events)-2].Tick > 0)
+}
+
+func TestParseEvent(t *testing.T) {
+	event := routines.Event{
+		Time:      time.Date(2023, 5, 6, 7, 8, 9, 10, time.UTC),
+		Tick:      42,
+		Kind:      routines.EventIteration,
+		Step:      routines.StepLoop,
+		ID:        3,
+		Parent:    1,
+		Iteration: 2,
+		File:      "/path with spaces/main.go",
+		Line:      17,
+	}
+
+	line := event.String()
+	test.Equal(t, line, "2023-05-06T07:08:09.00000001Z tick=42 iteration step=Loop id=3 parent=1 iteration=2 "+
+		"at=/path with spaces/main.go:17")
+
+	parsed, err := routines.ParseEvent(line)
+	test.Equal(t, err, nil)
+	test.True(t, parsed.Time.Equal(event.Time))
+	parsed.Time = event.Time
+	test.Equal(t, parsed, event)
+
+	_, err = routines.ParseEvent("2023-05-06T07:08:09Z tick=1 unknown")
+	test.True(t, err != nil)
+
+	_, err = routines.ParseEvent("not-time tick=1 reset")
+	test.True(t, err != nil)
+}
+
+func TestStepKind_IsWait(t *testing.T) {
+	test.False(t, routines.StepKind(0).IsWait())
+	for kind := routines.StepStart; kind.String() != ""; kind++ {
+		test.Equal(t, kind.IsWait(), strings.HasPrefix(kind.String(), "Wait") || kind == routines.StepSelect)
+	}
+}